@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	gwcmd "github.com/gin0606/gw/internal/cmd"
 	"github.com/gin0606/gw/internal/git"
 	"github.com/urfave/cli/v3"
 )
@@ -73,8 +74,8 @@ func completeRemove(ctx context.Context, cmd *cli.Command) {
 		}
 	}
 
-	// --force is a bool flag; the next argument is a positional arg, not a flag value
-	if prev != "--force" && strings.HasPrefix(prev, "-") {
+	// All rm flags are bool flags; the next argument is a positional arg, not a flag value
+	if prev != "--force" && prev != "--branch" && prev != "--delete-branch" && strings.HasPrefix(prev, "-") {
 		cli.DefaultCompleteWithFlags(ctx, cmd)
 		return
 	}
@@ -89,8 +90,100 @@ func completeRemove(ctx context.Context, cmd *cli.Command) {
 		return
 	}
 
+	// Skip the first worktree (main worktree)
+	byBranch := cmd.Bool("branch")
+	for _, wt := range worktrees[1:] {
+		if !byBranch {
+			fmt.Fprintln(cmd.Root().Writer, wt.Path)
+		}
+		if wt.Branch != "" {
+			fmt.Fprintln(cmd.Root().Writer, wt.Branch)
+		}
+	}
+}
+
+func completeWorktreeBranch(ctx context.Context, cmd *cli.Command) {
+	// No completion outside a git repository
+	repoRoot, err := git.RepoRoot(".")
+	if err != nil {
+		return
+	}
+
+	// Positional argument already provided; no further completion needed
+	if cmd.NArg() > 0 {
+		return
+	}
+
+	worktrees, err := git.ListWorktrees(repoRoot)
+	if err != nil {
+		return
+	}
+
+	for _, wt := range worktrees {
+		if wt.Branch != "" {
+			fmt.Fprintln(cmd.Root().Writer, wt.Branch)
+		}
+	}
+}
+
+func completeLocalBranch(ctx context.Context, cmd *cli.Command) {
+	// No completion outside a git repository
+	repoRoot, err := git.RepoRoot(".")
+	if err != nil {
+		return
+	}
+
+	// Positional argument already provided; no further completion needed
+	if cmd.NArg() > 0 {
+		return
+	}
+
+	branches, err := git.ListLocalBranches(repoRoot)
+	if err != nil {
+		return
+	}
+	for _, b := range branches {
+		fmt.Fprintln(cmd.Root().Writer, b)
+	}
+}
+
+func completeWorktreePath(ctx context.Context, cmd *cli.Command) {
+	// No completion outside a git repository
+	repoRoot, err := git.RepoRoot(".")
+	if err != nil {
+		return
+	}
+
+	worktrees, err := git.ListWorktrees(repoRoot)
+	if err != nil || len(worktrees) <= 1 {
+		return
+	}
+
 	// Skip the first worktree (main worktree)
 	for _, wt := range worktrees[1:] {
 		fmt.Fprintln(cmd.Root().Writer, wt.Path)
 	}
 }
+
+func completeHookRun(ctx context.Context, cmd *cli.Command) {
+	switch cmd.NArg() {
+	case 0:
+		for _, phase := range gwcmd.HookPhases {
+			fmt.Fprintln(cmd.Root().Writer, phase)
+		}
+	case 1:
+		repoRoot, err := git.RepoRoot(".")
+		if err != nil {
+			return
+		}
+		worktrees, err := git.ListWorktrees(repoRoot)
+		if err != nil {
+			return
+		}
+		for _, wt := range worktrees {
+			if wt.Branch != "" {
+				fmt.Fprintln(cmd.Root().Writer, wt.Branch)
+			}
+		}
+	}
+}
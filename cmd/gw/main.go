@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime/debug"
+	"syscall"
 
 	"github.com/gin0606/gw/internal/cmd"
 	"github.com/urfave/cli/v3"
@@ -22,19 +25,37 @@ func init() {
 }
 
 func main() {
+	cmd.Version = version
+
 	root := &cli.Command{
 		Name:                  "gw",
 		Usage:                 "A thin wrapper around git worktree",
 		Version:               version,
 		EnableShellCompletion: true,
-		Commands: []*cli.Command{
+		Commands: append([]*cli.Command{
 			cmdInit(),
 			cmdAdd(),
 			cmdRemove(),
+			cmdMove(),
 			cmdList(),
-		},
+			cmdPrune(),
+			cmdPath(),
+			cmdCd(),
+			cmdShellInit(),
+			cmdHook(),
+			cmdConfig(),
+		}, cmdPassthrough()...),
 	}
-	if err := root.Run(context.Background(), os.Args); err != nil {
+
+	// Cancel the context on Ctrl-C so that running hooks are terminated.
+	// Once canceled, further signals get the default behavior again.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := root.Run(ctx, os.Args); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
@@ -58,10 +79,11 @@ func cmdAdd() *cli.Command {
 	return &cli.Command{
 		Name:          "add",
 		Usage:         "Create a new worktree",
-		UsageText:     "gw add [--from <ref>] <branch>",
+		UsageText:     "gw add [--from <ref>] [--cd] <branch>",
 		ShellComplete: completeAdd,
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "from", Usage: "Create new branch from specified ref"},
+			&cli.BoolFlag{Name: "cd", Usage: "Change into the new worktree (requires gw shell-init)"},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			if c.Args().Len() < 1 {
@@ -70,7 +92,10 @@ func cmdAdd() *cli.Command {
 			if c.Args().Len() > 1 {
 				return fmt.Errorf("unexpected argument: %s", c.Args().Get(1))
 			}
-			return cmd.Add(c.Args().First(), c.String("from"))
+			if c.Bool("cd") {
+				cmd.WarnWithoutShellIntegration("gw add --cd")
+			}
+			return cmd.Add(ctx, c.Args().First(), c.String("from"))
 		},
 	}
 }
@@ -78,11 +103,14 @@ func cmdAdd() *cli.Command {
 func cmdRemove() *cli.Command {
 	return &cli.Command{
 		Name:          "rm",
+		Aliases:       []string{"remove"},
 		Usage:         "Remove a worktree",
-		UsageText:     "gw rm [--force] <path>",
+		UsageText:     "gw rm [--force] [--branch] [--delete-branch] <path|branch>",
 		ShellComplete: completeRemove,
 		Flags: []cli.Flag{
 			&cli.BoolFlag{Name: "force", Usage: "Force removal even if worktree is dirty or hook fails"},
+			&cli.BoolFlag{Name: "branch", Usage: "Treat the argument as a branch name"},
+			&cli.BoolFlag{Name: "delete-branch", Usage: "Delete the local branch after removing the worktree (must be merged unless --force)"},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			if c.Args().Len() < 1 {
@@ -91,7 +119,29 @@ func cmdRemove() *cli.Command {
 			if c.Args().Len() > 1 {
 				return fmt.Errorf("unexpected argument: %s", c.Args().Get(1))
 			}
-			return cmd.Remove(c.Args().First(), c.Bool("force"))
+			return cmd.Remove(ctx, c.Args().First(), cmd.RemoveOptions{
+				Force:        c.Bool("force"),
+				ByBranch:     c.Bool("branch"),
+				DeleteBranch: c.Bool("delete-branch"),
+			})
+		},
+	}
+}
+
+func cmdMove() *cli.Command {
+	return &cli.Command{
+		Name:          "mv",
+		Usage:         "Rename a branch and move its worktree",
+		UsageText:     "gw mv <old-branch> <new-branch>",
+		ShellComplete: completeWorktreeBranch,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Args().Len() < 2 {
+				return fmt.Errorf("old and new branch names required")
+			}
+			if c.Args().Len() > 2 {
+				return fmt.Errorf("unexpected argument: %s", c.Args().Get(2))
+			}
+			return cmd.Move(ctx, c.Args().Get(0), c.Args().Get(1))
 		},
 	}
 }
@@ -100,12 +150,319 @@ func cmdList() *cli.Command {
 	return &cli.Command{
 		Name:      "list",
 		Usage:     "List all worktrees",
-		UsageText: "gw list",
+		UsageText: "gw list [--status] [--json | --format <template>]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "json", Usage: "Output worktrees as a JSON array"},
+			&cli.StringFlag{Name: "format", Usage: "Format each worktree with a Go template (e.g. '{{.Branch}}\\t{{.Path}}')"},
+			&cli.BoolFlag{Name: "status", Usage: "Show dirty state, ahead/behind upstream, and merge state of each worktree"},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Args().Len() > 0 {
+				return fmt.Errorf("unexpected argument: %s", c.Args().First())
+			}
+			return cmd.List(cmd.ListOptions{
+				JSON:   c.Bool("json"),
+				Format: c.String("format"),
+				Status: c.Bool("status"),
+			})
+		},
+	}
+}
+
+func cmdPrune() *cli.Command {
+	return &cli.Command{
+		Name:      "prune",
+		Usage:     "Remove merged and stale worktrees",
+		UsageText: "gw prune [--dry-run] [--merged] [--gone] [--older-than <duration>]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "dry-run", Usage: "Only print the worktrees that would be removed"},
+			&cli.BoolFlag{Name: "merged", Usage: "Select worktrees merged into origin/<default branch>"},
+			&cli.BoolFlag{Name: "gone", Usage: "Select worktrees whose upstream branch is gone"},
+			&cli.StringFlag{Name: "older-than", Usage: "Only select worktrees whose HEAD commit is older than `duration` (e.g. 720h, 30d)"},
+		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			if c.Args().Len() > 0 {
 				return fmt.Errorf("unexpected argument: %s", c.Args().First())
 			}
-			return cmd.List()
+			return cmd.Prune(ctx, cmd.PruneOptions{
+				DryRun:    c.Bool("dry-run"),
+				Merged:    c.Bool("merged"),
+				Gone:      c.Bool("gone"),
+				OlderThan: c.String("older-than"),
+			})
 		},
 	}
 }
+
+func cmdPath() *cli.Command {
+	return &cli.Command{
+		Name:          "path",
+		Usage:         "Print the worktree path of a branch",
+		UsageText:     "gw path [--existing] <branch>",
+		ShellComplete: completeWorktreeBranch,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "existing", Usage: fmt.Sprintf("Fail with exit code %d unless the branch already has a worktree", exitNoWorktree)},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Args().Len() < 1 {
+				return fmt.Errorf("branch name required")
+			}
+			if c.Args().Len() > 1 {
+				return fmt.Errorf("unexpected argument: %s", c.Args().Get(1))
+			}
+			return noWorktreeExit(cmd.Path(c.Args().First(), c.Bool("existing")))
+		},
+	}
+}
+
+func cmdCd() *cli.Command {
+	return &cli.Command{
+		Name:          "cd",
+		Usage:         "Change into the worktree of a branch (requires gw shell-init)",
+		UsageText:     "gw cd <branch>",
+		ShellComplete: completeWorktreeBranch,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Args().Len() < 1 {
+				return fmt.Errorf("branch name required")
+			}
+			if c.Args().Len() > 1 {
+				return fmt.Errorf("unexpected argument: %s", c.Args().Get(1))
+			}
+			cmd.WarnWithoutShellIntegration("gw cd")
+			return noWorktreeExit(cmd.Path(c.Args().First(), true))
+		},
+	}
+}
+
+// exitNoWorktree is the exit code used when a branch has no worktree (gw path --existing, gw cd).
+const exitNoWorktree = 2
+
+func noWorktreeExit(err error) error {
+	if errors.Is(err, cmd.ErrNoWorktree) {
+		return cli.Exit(err, exitNoWorktree)
+	}
+	return err
+}
+
+func cmdShellInit() *cli.Command {
+	return &cli.Command{
+		Name:      "shell-init",
+		Usage:     "Print shell integration for gw cd and gw add --cd",
+		UsageText: "gw shell-init bash|zsh|fish",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Args().Len() < 1 {
+				return fmt.Errorf("shell name required (bash, zsh, fish)")
+			}
+			if c.Args().Len() > 1 {
+				return fmt.Errorf("unexpected argument: %s", c.Args().Get(1))
+			}
+			return cmd.ShellInit(c.Args().First())
+		},
+	}
+}
+
+func cmdHook() *cli.Command {
+	return &cli.Command{
+		Name:      "hook",
+		Usage:     "Manage repository hooks",
+		UsageText: "gw hook <command>",
+		Commands: []*cli.Command{
+			{
+				Name:      "trust",
+				Usage:     "Allow repository hooks to run (all hooks of the current repository by default)",
+				UsageText: "gw hook trust [<path>...]",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					return cmd.HookTrust(c.Args().Slice())
+				},
+			},
+			{
+				Name:      "untrust",
+				Usage:     "Revoke trust in repository hooks (all hooks of the current repository by default)",
+				UsageText: "gw hook untrust [<path>...]",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					return cmd.HookUntrust(c.Args().Slice())
+				},
+			},
+			{
+				Name:          "status",
+				Usage:         "Show the state of background hooks (of all worktrees by default)",
+				UsageText:     "gw hook status [<path|branch>]",
+				ShellComplete: completeWorktreeBranch,
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() > 1 {
+						return fmt.Errorf("unexpected argument: %s", c.Args().Get(1))
+					}
+					return cmd.HookStatus(c.Args().First())
+				},
+			},
+			{
+				Name:          "logs",
+				Usage:         "Print the output of the background hook of a worktree",
+				UsageText:     "gw hook logs [--follow] <path|branch>",
+				ShellComplete: completeWorktreeBranch,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "follow", Aliases: []string{"f"}, Usage: "Keep printing output until the hook exits"},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() < 1 {
+						return fmt.Errorf("path or branch required")
+					}
+					if c.Args().Len() > 1 {
+						return fmt.Errorf("unexpected argument: %s", c.Args().Get(1))
+					}
+					return cmd.HookLogs(ctx, c.Args().First(), c.Bool("follow"))
+				},
+			},
+			{
+				Name:          "run",
+				Usage:         "Run a hook for an existing worktree (the current one by default)",
+				UsageText:     "gw hook run <hook> [<path|branch>]",
+				ShellComplete: completeHookRun,
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() < 1 {
+						return fmt.Errorf("hook name required")
+					}
+					if c.Args().Len() > 2 {
+						return fmt.Errorf("unexpected argument: %s", c.Args().Get(2))
+					}
+					return cmd.HookRun(ctx, c.Args().First(), c.Args().Get(1))
+				},
+			},
+			{
+				Name:      "list",
+				Usage:     "List the scripts of each hook and whether they can run",
+				UsageText: "gw hook list",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() > 0 {
+						return fmt.Errorf("unexpected argument: %s", c.Args().First())
+					}
+					return cmd.HookList()
+				},
+			},
+			{
+				Name:   "run-background",
+				Usage:  "Run a background hook (used internally by gw add)",
+				Hidden: true,
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() != 1 {
+						return fmt.Errorf("job file required")
+					}
+					return cmd.RunBackgroundHook(ctx, c.Args().First())
+				},
+			},
+		},
+	}
+}
+
+func cmdConfig() *cli.Command {
+	return &cli.Command{
+		Name:      "config",
+		Usage:     "Inspect and edit the gw configuration",
+		UsageText: "gw config <command>",
+		Commands: []*cli.Command{
+			{
+				Name:      "get",
+				Usage:     "Print the value in effect for a key",
+				UsageText: "gw config get <key>",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() < 1 {
+						return fmt.Errorf("key required")
+					}
+					if c.Args().Len() > 1 {
+						return fmt.Errorf("unexpected argument: %s", c.Args().Get(1))
+					}
+					return cmd.ConfigGet(c.Args().First())
+				},
+			},
+			{
+				Name:      "set",
+				Usage:     "Set a key in .gw/config (or the global config file)",
+				UsageText: "gw config set [--global] <key> <value>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "global", Usage: "Edit the global config file"},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() < 2 {
+						return fmt.Errorf("key and value required")
+					}
+					if c.Args().Len() > 2 {
+						return fmt.Errorf("unexpected argument: %s", c.Args().Get(2))
+					}
+					return cmd.ConfigSet(c.Args().Get(0), c.Args().Get(1), c.Bool("global"))
+				},
+			},
+			{
+				Name:      "unset",
+				Usage:     "Remove a key from .gw/config (or the global config file)",
+				UsageText: "gw config unset [--global] <key>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "global", Usage: "Edit the global config file"},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() < 1 {
+						return fmt.Errorf("key required")
+					}
+					if c.Args().Len() > 1 {
+						return fmt.Errorf("unexpected argument: %s", c.Args().Get(1))
+					}
+					return cmd.ConfigUnset(c.Args().First(), c.Bool("global"))
+				},
+			},
+			{
+				Name:      "list",
+				Usage:     "List the keys set in the config files with their values and sources",
+				UsageText: "gw config list",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() > 0 {
+						return fmt.Errorf("unexpected argument: %s", c.Args().First())
+					}
+					return cmd.ConfigList()
+				},
+			},
+			{
+				Name:      "check",
+				Usage:     "Report unknown keys, invalid values and unsafe paths in the config files",
+				UsageText: "gw config check",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() > 0 {
+						return fmt.Errorf("unexpected argument: %s", c.Args().First())
+					}
+					return cmd.ConfigCheck()
+				},
+			},
+			{
+				Name:          "explain",
+				Usage:         "Show the rule that matches a branch and the settings in effect for it",
+				UsageText:     "gw config explain <branch>",
+				ShellComplete: completeLocalBranch,
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() < 1 {
+						return fmt.Errorf("branch name required")
+					}
+					if c.Args().Len() > 1 {
+						return fmt.Errorf("unexpected argument: %s", c.Args().Get(1))
+					}
+					return cmd.ConfigExplain(c.Args().First())
+				},
+			},
+		},
+	}
+}
+
+func cmdPassthrough() []*cli.Command {
+	var cmds []*cli.Command
+	for _, name := range cmd.PassthroughCommands {
+		cmds = append(cmds, &cli.Command{
+			Name:            name,
+			Usage:           fmt.Sprintf("Run 'git worktree %s' in the main repository", name),
+			UsageText:       fmt.Sprintf("gw %s [<git worktree %s arguments>...]", name, name),
+			Category:        "git worktree passthrough",
+			SkipFlagParsing: true,
+			ShellComplete:   completeWorktreePath,
+			Action: func(ctx context.Context, c *cli.Command) error {
+				return cmd.Passthrough(name, c.Args().Slice())
+			},
+		})
+	}
+	return cmds
+}
@@ -2,12 +2,22 @@ package main_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
+	"unsafe"
 
 	"github.com/gin0606/gw/internal/testutil"
 )
@@ -20,6 +30,16 @@ func TestMain(m *testing.M) {
 		panic(err)
 	}
 
+	// Keep the developer's own global config and hooks out of the tests.
+	configHome := filepath.Join(dir, "config")
+	if err := os.Mkdir(configHome, 0755); err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", configHome)
+	os.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	// Most tests write hooks directly; the trust check is exercised by the gw hook tests.
+	os.Setenv("GW_TRUST_ALL_HOOKS", "1")
+
 	gwBinary = filepath.Join(dir, "gw")
 	buildCmd := exec.Command("go", "build", "-o", gwBinary, ".")
 	if out, err := buildCmd.CombinedOutput(); err != nil {
@@ -376,6 +396,415 @@ func TestAdd_PostAddHook_Failure(t *testing.T) {
 	}
 }
 
+func TestAdd_HookEnvironment(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateTag("v1.0.0")
+	repo.CreateBranch("existing-env")
+	logFile := filepath.Join(t.TempDir(), "env.log")
+	script := "#!/bin/sh\n" +
+		"echo \"$GW_HOOK_NAME repo=$GW_REPO_NAME base=$GW_BASE_REF created=$GW_BRANCH_CREATED " +
+		"sha=$GW_HEAD_SHA name=$GW_SANITIZED_NAME version=$GW_VERSION\" >> " + logFile + "\n"
+	repo.WriteHook("pre-add", script)
+	repo.WriteHook("post-add", script)
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "feature/env", "--from", "v1.0.0"); exitCode != 0 {
+		t.Fatalf("exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "existing-env"); exitCode != 0 {
+		t.Fatalf("exit code = %d; stderr: %s", exitCode, stderr)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha := repo.HeadSHA("main")
+	repoName := filepath.Base(repo.Root)
+	versionOut, _, _ := runGw(t, repo.Root, "--version")
+	fields := strings.Fields(versionOut)
+	version := fields[len(fields)-1]
+	want := []string{
+		"pre-add repo=" + repoName + " base=v1.0.0 created=true sha=" + sha + " name=feature-env version=" + version,
+		"post-add repo=" + repoName + " base=v1.0.0 created=true sha=" + sha + " name=feature-env version=" + version,
+		"pre-add repo=" + repoName + " base= created=false sha=" + sha + " name=existing-env version=" + version,
+		"post-add repo=" + repoName + " base= created=false sha=" + sha + " name=existing-env version=" + version,
+	}
+	if got := strings.Split(strings.TrimSpace(string(data)), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestAdd_HookEvent(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	outFile := filepath.Join(t.TempDir(), "event.json")
+	repo.WriteHook("post-add", "#!/bin/sh\ncat > "+outFile+"\n")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "add", "feature/event")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	wtPath := strings.TrimSpace(stdout)
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ev struct {
+		Version  int    `json:"version"`
+		Hook     string `json:"hook"`
+		Branch   string `json:"branch"`
+		BaseRef  string `json:"base_ref"`
+		Worktree struct {
+			Path          string `json:"path"`
+			SanitizedName string `json:"sanitized_name"`
+			HeadSHA       string `json:"head_sha"`
+		} `json:"worktree"`
+		Flags map[string]bool `json:"flags"`
+	}
+	if err := json.Unmarshal(data, &ev); err != nil {
+		t.Fatalf("invalid JSON on stdin: %v\n%s", err, data)
+	}
+	if ev.Version != 1 || ev.Hook != "post-add" || ev.Branch != "feature/event" || ev.BaseRef != "origin/main" {
+		t.Errorf("unexpected event: %s", data)
+	}
+	if ev.Worktree.Path != wtPath || ev.Worktree.SanitizedName != "feature-event" || ev.Worktree.HeadSHA != repo.HeadSHA("main") {
+		t.Errorf("unexpected worktree in event: %s", data)
+	}
+	if !ev.Flags["branch_created"] {
+		t.Errorf("expected branch_created flag: %s", data)
+	}
+}
+
+func TestAdd_DefaultBaseRef(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	logFile := filepath.Join(t.TempDir(), "env.log")
+	repo.WriteHook("pre-add", "#!/bin/sh\necho \"$GW_BASE_REF\" > "+logFile+"\n")
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "feature/default-base"); exitCode != 0 {
+		t.Fatalf("exit code = %d; stderr: %s", exitCode, stderr)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "origin/main" {
+		t.Errorf("GW_BASE_REF = %q, want %q", got, "origin/main")
+	}
+}
+
+func TestAdd_InvalidFrom_HookNotRun(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	markerFile := filepath.Join(t.TempDir(), "hook-ran.txt")
+	repo.WriteHook("pre-add", "#!/bin/sh\ntouch "+markerFile+"\n")
+
+	_, _, exitCode := runGw(t, repo.Root, "add", "feature/bad-from", "--from", "no-such-ref")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if _, err := os.Stat(markerFile); err == nil {
+		t.Error("pre-add hook should not run when --from cannot be resolved")
+	}
+}
+
+func TestAdd_HookDirectory(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("post-add.d/10-fail", "#!/bin/sh\nexit 1\n")
+	repo.WriteHook("post-add.d/20-ok", "#!/bin/sh\ntouch \"$GW_WORKTREE_PATH/setup-done\"\n")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "add", "feature/hook-dir")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if !strings.Contains(stderr, "gw: warning: post-add hook failed: post-add.d/10-fail") {
+		t.Errorf("expected warning naming the failed script, got: %q", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(strings.TrimSpace(stdout), "setup-done")); err != nil {
+		t.Errorf("post-add.d/20-ok should have run: %v", err)
+	}
+}
+
+func TestAdd_PreAddHookDirectory_Failure(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("pre-add.d/10-check", "#!/bin/sh\nexit 1\n")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feature/hook-dir-fail")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "pre-add hook failed: pre-add.d/10-check") {
+		t.Errorf("expected error naming the failed script, got: %q", stderr)
+	}
+}
+
+func TestAdd_GlobalConfigAndHooks(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	logFile := filepath.Join(t.TempDir(), "hooks.log")
+
+	globalDir := filepath.Join(xdg, "gw")
+	if err := os.MkdirAll(filepath.Join(globalDir, "hooks"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(globalDir, "config"), []byte(`worktrees_dir = "global-trees"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(globalDir, "hooks", "post-add"), []byte("#!/bin/sh\necho global >> "+logFile+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo.WriteHook("post-add", "#!/bin/sh\necho repo >> "+logFile+"\n")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "add", "feature/global")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if want := filepath.Join(repo.Root, "global-trees", "feature-global"); strings.TrimSpace(stdout) != want {
+		t.Errorf("got path %q, want %q", strings.TrimSpace(stdout), want)
+	}
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "repo\nglobal\n" {
+		t.Errorf("post-add order = %q, want repo then global", string(data))
+	}
+
+	// Repository config overrides the global one
+	repo.WriteConfig(`worktrees_dir = "repo-trees"`)
+	stdout, _, _ = runGw(t, repo.Root, "path", "feature/other")
+	if want := filepath.Join(repo.Root, "repo-trees", "feature-other"); strings.TrimSpace(stdout) != want {
+		t.Errorf("got path %q, want %q", strings.TrimSpace(stdout), want)
+	}
+}
+
+func TestAdd_PreAddHook_Timeout(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("[hooks.pre-add]\ntimeout = \"200ms\"\n")
+	repo.WriteHook("pre-add", "#!/bin/sh\nsleep 30\n")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feature/hook-timeout")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "pre-add hook failed") || !strings.Contains(stderr, "timed out after 200ms") {
+		t.Errorf("expected timeout error in stderr, got: %q", stderr)
+	}
+
+	repoName := filepath.Base(repo.Root)
+	wtPath := filepath.Join(filepath.Dir(repo.Root), repoName+"-worktrees", "feature-hook-timeout")
+	if _, err := os.Stat(wtPath); err == nil {
+		t.Error("worktree should not have been created when pre-add hook times out")
+	}
+}
+
+func TestAdd_PostAddHook_Timeout(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("[hooks.post-add]\ntimeout = \"200ms\"\n")
+	repo.WriteHook("post-add", "#!/bin/sh\nsleep 30\n")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "add", "feature/post-timeout")
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+	if !strings.Contains(stderr, "gw: warning: post-add hook failed") || !strings.Contains(stderr, "timed out") {
+		t.Errorf("expected timeout warning in stderr, got: %q", stderr)
+	}
+	if _, err := os.Stat(strings.TrimSpace(stdout)); err != nil {
+		t.Errorf("worktree should have been created even when post-add hook times out: %v", err)
+	}
+}
+
+func TestAdd_Interrupt_KillsHook(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	repo.WriteHook("pre-add", "#!/bin/sh\nsleep 30 &\necho $! > "+pidFile+".tmp\nmv "+pidFile+".tmp "+pidFile+"\nwait\n")
+
+	cmd := exec.Command(gwBinary, "add", "feature/interrupted")
+	cmd.Dir = repo.Root
+	var errBuf bytes.Buffer
+	cmd.Stderr = &errBuf
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var pidData []byte
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		var err error
+		if pidData, err = os.ReadFile(pidFile); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = cmd.Process.Kill()
+			t.Fatal("pre-add hook did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	err := cmd.Wait()
+
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		t.Errorf("expected exit code 1, got: %v", err)
+	}
+	if !strings.Contains(errBuf.String(), "interrupted") {
+		t.Errorf("expected 'interrupted' in stderr, got: %q", errBuf.String())
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidData)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline = time.Now().Add(3 * time.Second)
+	for syscall.Kill(pid, 0) == nil {
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("child process %d of the hook is still running", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// startGwOnTerminal runs gw in a new session whose controlling terminal is a new pseudo-terminal,
+// as in an interactive shell. It returns the terminal's master side; the output written to the
+// terminal is collected in output. Linux only.
+func startGwOnTerminal(t *testing.T, dir string, output *syncBuffer, args ...string) (*exec.Cmd, *os.File) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("pseudo-terminal tests are only supported on Linux")
+	}
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo-terminals are not available: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+	ioctl := func(req uintptr, arg unsafe.Pointer) {
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), req, uintptr(arg)); errno != 0 {
+			t.Fatalf("ioctl %#x: %v", req, errno)
+		}
+	}
+	var unlock int32
+	ioctl(0x40045431, unsafe.Pointer(&unlock)) // TIOCSPTLCK
+	var n uint32
+	ioctl(0x80045430, unsafe.Pointer(&n)) // TIOCGPTN
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slave.Close()
+
+	cmd := exec.Command(gwBinary, args...)
+	cmd.Dir = dir
+	cmd.Stdin = slave
+	cmd.Stdout = slave
+	cmd.Stderr = slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	go io.Copy(output, master)
+	return cmd, master
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitTimeout waits for cmd to exit, killing it and failing the test after timeout.
+func waitTimeout(t *testing.T, cmd *exec.Cmd, timeout time.Duration, output *syncBuffer) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		_ = cmd.Process.Kill()
+		<-done
+		t.Fatalf("gw did not exit within %s; terminal output: %q", timeout, output.String())
+		return nil
+	}
+}
+
+func TestAdd_HookReadsTerminal(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	answerFile := filepath.Join(t.TempDir(), "answer")
+	repo.WriteHook("pre-add", "#!/bin/sh\nprintf 'password: '\nread answer </dev/tty\necho \"$answer\" > "+answerFile+"\n")
+
+	var output syncBuffer
+	cmd, master := startGwOnTerminal(t, repo.Root, &output, "add", "feature/tty")
+	if _, err := master.Write([]byte("secret\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the terminal, the hook would be stopped by SIGTTIN and gw would never exit.
+	if err := waitTimeout(t, cmd, 15*time.Second, &output); err != nil {
+		t.Fatalf("gw add failed: %v; terminal output: %q", err, output.String())
+	}
+	data, err := os.ReadFile(answerFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(data)) != "secret" {
+		t.Errorf("hook read %q from the terminal, want %q", data, "secret")
+	}
+}
+
+func TestAdd_HookOnTerminal_CtrlC(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	readyFile := filepath.Join(t.TempDir(), "ready")
+	repo.WriteHook("pre-add", "#!/bin/sh\ntouch "+readyFile+"\nsleep 30\n")
+
+	var output syncBuffer
+	cmd, master := startGwOnTerminal(t, repo.Root, &output, "add", "feature/tty-interrupted")
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, err := os.Stat(readyFile); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = cmd.Process.Kill()
+			t.Fatal("pre-add hook did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Ctrl-C reaches the hook, which has the terminal; gw must stop as well.
+	if _, err := master.Write([]byte{0x03}); err != nil {
+		t.Fatal(err)
+	}
+	err := waitTimeout(t, cmd, 15*time.Second, &output)
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		t.Errorf("expected exit code 1, got: %v", err)
+	}
+	if !strings.Contains(output.String(), "interrupted") {
+		t.Errorf("expected 'interrupted' in terminal output, got: %q", output.String())
+	}
+}
+
 func TestAdd_DirectoryCollision(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 
@@ -457,534 +886,3263 @@ func TestAdd_ExistingBranch_WithFrom_PreAddNotExecuted(t *testing.T) {
 	}
 }
 
-// --- gw list ---
+// --- gw mv ---
 
-func TestList_Basic(t *testing.T) {
+func TestMv_Basic(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/list-test")
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/old-name")
 	if exitCode != 0 {
 		t.Fatalf("gw add exit code = %d, want 0", exitCode)
 	}
-	wtPath := strings.TrimSpace(addStdout)
+	oldPath := strings.TrimSpace(addStdout)
 
-	// List worktrees
-	stdout, _, exitCode := runGw(t, repo.Root, "list")
+	stdout, stderr, exitCode := runGw(t, repo.Root, "mv", "feature/old-name", "feature/new-name")
 
 	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
-
-	if !strings.Contains(stdout, wtPath) {
-		t.Errorf("expected list to contain %q, got: %q", wtPath, stdout)
+	newPath := strings.TrimSpace(stdout)
+	if newPath != filepath.Join(filepath.Dir(oldPath), "feature-new-name") {
+		t.Errorf("got new path %q", newPath)
 	}
-
-	if !strings.Contains(stdout, repo.Root) {
-		t.Errorf("expected list to contain repo root %q, got: %q", repo.Root, stdout)
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("old worktree path should not exist: %s", oldPath)
 	}
-}
-
-func TestList_NoWorktrees(t *testing.T) {
-	repo := testutil.NewTestRepo(t)
-
-	stdout, _, exitCode := runGw(t, repo.Root, "list")
 
-	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+	pathOut, _, exitCode := runGw(t, repo.Root, "path", "--existing", "feature/new-name")
+	if exitCode != 0 || strings.TrimSpace(pathOut) != newPath {
+		t.Errorf("gw path for new branch = %q (exit %d), want %q", pathOut, exitCode, newPath)
 	}
-
-	// Should contain at least the main repo
-	if !strings.Contains(stdout, repo.Root) {
-		t.Errorf("expected list to contain repo root %q, got: %q", repo.Root, stdout)
+	if _, _, exitCode := runGw(t, repo.Root, "path", "--existing", "feature/old-name"); exitCode != 2 {
+		t.Errorf("old branch should have no worktree, exit code = %d", exitCode)
 	}
 }
 
-func TestList_MultipleWorktrees(t *testing.T) {
+func TestMv_Hooks(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
-
-	addStdout1, _, exitCode := runGw(t, repo.Root, "add", "feature/list-one")
+	logFile := filepath.Join(t.TempDir(), "hooks.log")
+	script := "#!/bin/sh\n" +
+		"echo \"$0 cwd=$(pwd -P) branch=$GW_BRANCH path=$GW_WORKTREE_PATH " +
+		"old=$GW_OLD_BRANCH new=$GW_NEW_BRANCH oldpath=$GW_OLD_WORKTREE_PATH newpath=$GW_NEW_WORKTREE_PATH\" >> " + logFile + "\n"
+	repo.WriteHook("pre-move", script)
+	repo.WriteHook("post-move", script)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "mv-hook-old")
 	if exitCode != 0 {
 		t.Fatalf("gw add exit code = %d, want 0", exitCode)
 	}
-	wt1 := strings.TrimSpace(addStdout1)
+	oldPath := strings.TrimSpace(addStdout)
 
-	addStdout2, _, exitCode := runGw(t, repo.Root, "add", "feature/list-two")
+	stdout, stderr, exitCode := runGw(t, repo.Root, "mv", "mv-hook-old", "mv-hook-new")
 	if exitCode != 0 {
-		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
-	wt2 := strings.TrimSpace(addStdout2)
-
-	stdout, _, exitCode := runGw(t, repo.Root, "list")
+	newPath := strings.TrimSpace(stdout)
 
-	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
 	}
-
-	if !strings.Contains(stdout, wt1) {
-		t.Errorf("expected list to contain %q, got: %q", wt1, stdout)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 hook invocations, got: %q", string(data))
 	}
-	if !strings.Contains(stdout, wt2) {
-		t.Errorf("expected list to contain %q, got: %q", wt2, stdout)
+	common := " old=mv-hook-old new=mv-hook-new oldpath=" + oldPath + " newpath=" + newPath
+	wantPre := "cwd=" + oldPath + " branch=mv-hook-old path=" + oldPath + common
+	wantPost := "cwd=" + newPath + " branch=mv-hook-new path=" + newPath + common
+	if !strings.HasSuffix(lines[0], wantPre) || !strings.Contains(lines[0], "pre-move") {
+		t.Errorf("pre-move got %q, want suffix %q", lines[0], wantPre)
+	}
+	if !strings.HasSuffix(lines[1], wantPost) || !strings.Contains(lines[1], "post-move") {
+		t.Errorf("post-move got %q, want suffix %q", lines[1], wantPost)
 	}
 }
 
-func TestList_FromWorktree(t *testing.T) {
+func TestMv_PreMoveHook_Failure(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("pre-move", "#!/bin/sh\nexit 1\n")
 
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/list-from-wt")
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "mv-abort")
 	if exitCode != 0 {
 		t.Fatalf("gw add exit code = %d, want 0", exitCode)
 	}
-	wtPath := strings.TrimSpace(addStdout)
+	oldPath := strings.TrimSpace(addStdout)
 
-	// Run list from inside the worktree
-	stdout, _, exitCode := runGw(t, wtPath, "list")
+	_, stderr, exitCode := runGw(t, repo.Root, "mv", "mv-abort", "mv-aborted")
 
-	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
 	}
-
-	if !strings.Contains(stdout, repo.Root) {
-		t.Errorf("expected list to contain repo root %q, got: %q", repo.Root, stdout)
+	if !strings.Contains(stderr, "pre-move hook failed") {
+		t.Errorf("expected 'pre-move hook failed' in stderr, got: %q", stderr)
 	}
-	if !strings.Contains(stdout, wtPath) {
-		t.Errorf("expected list to contain worktree %q, got: %q", wtPath, stdout)
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Errorf("worktree should not have been moved: %v", err)
+	}
+	branchOut, _ := exec.Command("git", "-C", repo.Root, "branch", "--list", "mv-abort").Output()
+	if strings.TrimSpace(string(branchOut)) == "" {
+		t.Error("branch should not have been renamed")
 	}
 }
 
-func TestList_ExtraArgs(t *testing.T) {
+func TestMv_RollbackOnMoveFailure(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktreeInBaseDir("mv-locked")
+	repo.LockWorktree(wtPath, "keep")
 
-	_, stderr, exitCode := runGw(t, repo.Root, "list", "extra")
+	_, stderr, exitCode := runGw(t, repo.Root, "mv", "mv-locked", "mv-unlocked")
 
 	if exitCode != 1 {
 		t.Errorf("exit code = %d, want 1", exitCode)
 	}
-	if !strings.Contains(stderr, "unexpected argument") {
-		t.Errorf("expected 'unexpected argument' in stderr, got: %q", stderr)
+	if !strings.Contains(stderr, "git worktree move failed") {
+		t.Errorf("expected move failure in stderr, got: %q", stderr)
 	}
-}
 
-func TestList_OutsideGitRepo(t *testing.T) {
-	tmpDir := t.TempDir()
+	branchOut, _ := exec.Command("git", "-C", repo.Root, "branch", "--list", "mv-locked", "mv-unlocked").Output()
+	if strings.TrimSpace(string(branchOut)) != "mv-locked" && !strings.HasSuffix(strings.TrimSpace(string(branchOut)), " mv-locked") {
+		t.Errorf("branch rename should have been rolled back, got: %q", string(branchOut))
+	}
+	pathOut, _, _ := runGw(t, repo.Root, "path", "--existing", "mv-locked")
+	if strings.TrimSpace(pathOut) != wtPath {
+		t.Errorf("worktree should still be at %s, got: %q", wtPath, pathOut)
+	}
+}
 
-	_, _, exitCode := runGw(t, tmpDir, "list")
+func TestMv_NewBranchExists(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateWorktreeInBaseDir("mv-src")
+	repo.CreateBranch("mv-taken")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "mv", "mv-src", "mv-taken")
 
 	if exitCode != 1 {
 		t.Errorf("exit code = %d, want 1", exitCode)
 	}
+	if !strings.Contains(stderr, "already exists") {
+		t.Errorf("expected 'already exists' in stderr, got: %q", stderr)
+	}
 }
 
-// --- gw rm ---
+func TestMv_NotCheckedOut(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateBranch("mv-no-worktree")
 
-func TestRm_Basic(t *testing.T) {
+	_, stderr, exitCode := runGw(t, repo.Root, "mv", "mv-no-worktree", "mv-other")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "not checked out") {
+		t.Errorf("expected 'not checked out' in stderr, got: %q", stderr)
+	}
+}
+
+func TestMv_MainWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "mv", "main", "trunk")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "cannot move the main worktree") {
+		t.Errorf("expected main worktree error, got: %q", stderr)
+	}
+}
+
+func TestMv_SameSanitizedPath(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "same/path")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "mv", "same/path", "same-path")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != wtPath {
+		t.Errorf("got %q, want unchanged path %q", strings.TrimSpace(stdout), wtPath)
+	}
+}
+
+func TestMv_Args(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "mv", "only-one")
+	if exitCode != 1 || !strings.Contains(stderr, "branch names required") {
+		t.Errorf("exit code = %d, stderr = %q", exitCode, stderr)
+	}
+
+	_, stderr, exitCode = runGw(t, repo.Root, "mv", "a", "b", "c")
+	if exitCode != 1 || !strings.Contains(stderr, "unexpected argument") {
+		t.Errorf("exit code = %d, stderr = %q", exitCode, stderr)
+	}
+}
+
+// --- gw list ---
+
+func TestList_Basic(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 
 	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/rm-test")
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/list-test")
 	if exitCode != 0 {
 		t.Fatalf("gw add exit code = %d, want 0", exitCode)
 	}
 	wtPath := strings.TrimSpace(addStdout)
 
-	// Remove it
-	stdout, _, exitCode := runGw(t, repo.Root, "rm", wtPath)
+	// List worktrees
+	stdout, _, exitCode := runGw(t, repo.Root, "list")
 
 	if exitCode != 0 {
 		t.Errorf("exit code = %d, want 0", exitCode)
 	}
 
-	// stdout should be empty
-	if strings.TrimSpace(stdout) != "" {
-		t.Errorf("expected empty stdout, got: %q", stdout)
+	if !strings.Contains(stdout, wtPath) {
+		t.Errorf("expected list to contain %q, got: %q", wtPath, stdout)
 	}
 
-	// Worktree directory should be gone
-	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
-		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	if !strings.Contains(stdout, repo.Root) {
+		t.Errorf("expected list to contain repo root %q, got: %q", repo.Root, stdout)
 	}
 }
 
-func TestRm_UncommittedChanges_WithoutForce(t *testing.T) {
+func TestList_NoWorktrees(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/dirty-no-force")
+	stdout, _, exitCode := runGw(t, repo.Root, "list")
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+
+	// Should contain at least the main repo
+	if !strings.Contains(stdout, repo.Root) {
+		t.Errorf("expected list to contain repo root %q, got: %q", repo.Root, stdout)
+	}
+}
+
+func TestList_MultipleWorktrees(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout1, _, exitCode := runGw(t, repo.Root, "add", "feature/list-one")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wt1 := strings.TrimSpace(addStdout1)
+
+	addStdout2, _, exitCode := runGw(t, repo.Root, "add", "feature/list-two")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wt2 := strings.TrimSpace(addStdout2)
+
+	stdout, _, exitCode := runGw(t, repo.Root, "list")
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+
+	if !strings.Contains(stdout, wt1) {
+		t.Errorf("expected list to contain %q, got: %q", wt1, stdout)
+	}
+	if !strings.Contains(stdout, wt2) {
+		t.Errorf("expected list to contain %q, got: %q", wt2, stdout)
+	}
+}
+
+func TestList_FromWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/list-from-wt")
 	if exitCode != 0 {
 		t.Fatalf("gw add exit code = %d, want 0", exitCode)
 	}
 	wtPath := strings.TrimSpace(addStdout)
 
-	// Create uncommitted changes in the worktree
-	if err := os.WriteFile(filepath.Join(wtPath, "dirty.txt"), []byte("dirty"), 0644); err != nil {
-		t.Fatal(err)
+	// Run list from inside the worktree
+	stdout, _, exitCode := runGw(t, wtPath, "list")
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
 	}
-	gitAdd := exec.Command("git", "add", "dirty.txt")
-	gitAdd.Dir = wtPath
-	if err := gitAdd.Run(); err != nil {
-		t.Fatal(err)
+
+	if !strings.Contains(stdout, repo.Root) {
+		t.Errorf("expected list to contain repo root %q, got: %q", repo.Root, stdout)
+	}
+	if !strings.Contains(stdout, wtPath) {
+		t.Errorf("expected list to contain worktree %q, got: %q", wtPath, stdout)
 	}
+}
 
-	// Remove without --force should fail
-	_, _, exitCode = runGw(t, repo.Root, "rm", wtPath)
+func TestList_ExtraArgs(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "list", "extra")
 
 	if exitCode != 1 {
 		t.Errorf("exit code = %d, want 1", exitCode)
 	}
+	if !strings.Contains(stderr, "unexpected argument") {
+		t.Errorf("expected 'unexpected argument' in stderr, got: %q", stderr)
+	}
+}
 
-	// Worktree should still exist
-	if _, err := os.Stat(wtPath); os.IsNotExist(err) {
-		t.Error("worktree should not have been removed without --force")
+func TestList_OutsideGitRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	_, _, exitCode := runGw(t, tmpDir, "list")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
 	}
 }
 
-func TestRm_Force_UncommittedChanges(t *testing.T) {
+func TestList_JSON(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/force-rm")
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/list-json")
 	if exitCode != 0 {
 		t.Fatalf("gw add exit code = %d, want 0", exitCode)
 	}
 	wtPath := strings.TrimSpace(addStdout)
 
-	// Create uncommitted changes in the worktree
-	if err := os.WriteFile(filepath.Join(wtPath, "dirty.txt"), []byte("dirty"), 0644); err != nil {
-		t.Fatal(err)
+	stdout, stderr, exitCode := runGw(t, repo.Root, "list", "--json")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
-	gitAdd := exec.Command("git", "add", "dirty.txt")
-	gitAdd.Dir = wtPath
-	if err := gitAdd.Run(); err != nil {
-		t.Fatal(err)
+
+	var entries []struct {
+		Path     string `json:"path"`
+		Branch   string `json:"branch"`
+		Head     string `json:"head"`
+		Main     bool   `json:"main"`
+		Detached bool   `json:"detached"`
+		Locked   bool   `json:"locked"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil {
+		t.Fatalf("failed to parse JSON output: %v\n%s", err, stdout)
 	}
 
-	// Remove with --force
-	_, _, exitCode = runGw(t, repo.Root, "rm", wtPath, "--force")
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %s", len(entries), stdout)
+	}
+	if entries[0].Path != repo.Root || !entries[0].Main || entries[0].Branch != "main" {
+		t.Errorf("unexpected main worktree entry: %+v", entries[0])
+	}
+	if entries[1].Path != wtPath || entries[1].Main || entries[1].Branch != "feature/list-json" {
+		t.Errorf("unexpected worktree entry: %+v", entries[1])
+	}
+	if entries[1].Head == "" {
+		t.Error("expected head to be set")
+	}
+}
+
+func TestList_JSON_LockedAndDetached(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	detached := repo.CreateDetachedWorktree("json-detached")
+	locked := repo.CreateWorktree("json-locked", "json-locked")
+	repo.LockWorktree(locked, "keep")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "list", "--json")
 
 	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
 
-	// Worktree directory should be gone
-	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
-		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	var entries []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil {
+		t.Fatalf("failed to parse JSON output: %v\n%s", err, stdout)
+	}
+
+	byPath := map[string]map[string]any{}
+	for _, e := range entries {
+		byPath[e["path"].(string)] = e
+	}
+	if e := byPath[detached]; e == nil || e["detached"] != true {
+		t.Errorf("expected detached entry for %q, got: %v", detached, e)
+	}
+	if e := byPath[locked]; e == nil || e["locked"] != true || e["locked_reason"] != "keep" {
+		t.Errorf("expected locked entry for %q, got: %v", locked, e)
 	}
 }
 
-func TestRm_PreRemoveHook_Failure_NoForce(t *testing.T) {
+func TestList_Format(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/hook-rm")
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/list-format")
 	if exitCode != 0 {
 		t.Fatalf("gw add exit code = %d, want 0", exitCode)
 	}
 	wtPath := strings.TrimSpace(addStdout)
 
-	// Set up failing pre-remove hook
-	repo.WriteHook("pre-remove", "#!/bin/sh\nexit 1\n")
+	stdout, stderr, exitCode := runGw(t, repo.Root, "list", "--format", `{{.Branch}}\t{{.Path}}`)
 
-	// Try to remove
-	_, _, exitCode = runGw(t, repo.Root, "rm", wtPath)
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	want := []string{"main\t" + repo.Root, "feature/list-format\t" + wtPath}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), len(want), stdout)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestList_Format_EscapesInActions(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Escapes inside string literals are left to the template; outside actions they are expanded.
+	// Quotes in comments do not start string literals.
+	stdout, stderr, exitCode := runGw(t, repo.Root, "list", "--format", `{{printf "%s\t" .Branch}}|{{printf "}}\n"}}{{/* don't */}}\t.`)
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if want := "main\t|}}\n\t.\n"; stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+}
+
+func TestList_Format_InvalidTemplate(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "list", "--format", "{{.Branch")
 
 	if exitCode != 1 {
 		t.Errorf("exit code = %d, want 1", exitCode)
 	}
+	if stdout != "" {
+		t.Errorf("expected empty stdout, got: %q", stdout)
+	}
+	if !strings.Contains(stderr, "invalid format template") {
+		t.Errorf("expected 'invalid format template' in stderr, got: %q", stderr)
+	}
+}
 
-	// Worktree should still exist
-	if _, err := os.Stat(wtPath); os.IsNotExist(err) {
-		t.Error("worktree should not have been removed when pre-remove hook fails without --force")
+func TestList_Format_UnknownField(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "list", "--format", "{{.Nope}}")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "failed to render format template") {
+		t.Errorf("expected render error in stderr, got: %q", stderr)
 	}
 }
 
-func TestRm_PreRemoveHook_Failure_WithForce(t *testing.T) {
+func TestList_Format_WithJSON(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/hook-force-rm")
+	_, stderr, exitCode := runGw(t, repo.Root, "list", "--json", "--format", "{{.Path}}")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "cannot be used together") {
+		t.Errorf("expected conflict error in stderr, got: %q", stderr)
+	}
+}
+
+// mergeWorktree commits to the worktree of branch and merges branch into origin/main with a merge commit.
+func mergeWorktree(repo *testutil.TestRepo, wtPath, branch string) {
+	repo.CommitFile(wtPath, "merged.txt", "merged")
+	repo.MergeBranchNoFF(branch)
+	repo.PushBranch("main")
+}
+
+func TestList_Status(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/status-merged")
 	if exitCode != 0 {
 		t.Fatalf("gw add exit code = %d, want 0", exitCode)
 	}
-	wtPath := strings.TrimSpace(addStdout)
-
-	// Set up failing pre-remove hook
-	repo.WriteHook("pre-remove", "#!/bin/sh\nexit 1\n")
+	mergedPath := strings.TrimSpace(addStdout)
+	mergeWorktree(repo, mergedPath, "feature/status-merged")
 
-	// Remove with --force
-	_, stderr, exitCode := runGw(t, repo.Root, "rm", wtPath, "--force")
+	addStdout, _, exitCode = runGw(t, repo.Root, "add", "feature/status-ff")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	ffPath := strings.TrimSpace(addStdout)
+	repo.CommitFile(ffPath, "ff.txt", "ff")
+	repo.MergeBranch("feature/status-ff")
+	repo.PushBranch("main")
 
+	addStdout, _, exitCode = runGw(t, repo.Root, "add", "feature/status-dirty")
 	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	dirtyPath := strings.TrimSpace(addStdout)
+	if err := os.WriteFile(filepath.Join(dirtyPath, "dirty.txt"), []byte("dirty"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	addStdout, _, exitCode = runGw(t, repo.Root, "add", "feature/status-ahead")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	aheadPath := strings.TrimSpace(addStdout)
+	repo.CommitFile(aheadPath, "ahead.txt", "ahead")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "list", "--status")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+
+	lines := map[string][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		fields := strings.Fields(line)
+		lines[fields[0]] = fields[1:]
+	}
+
+	// Branches without commits of their own, including main itself, are not merged.
+	if got := lines[dirtyPath]; len(got) != 4 || got[1] != "dirty" || got[3] != "-" {
+		t.Errorf("unexpected status for dirty worktree: %v", got)
+	}
+	if got := lines[repo.Root]; len(got) != 4 || got[3] != "-" {
+		t.Errorf("unexpected status for main worktree: %v", got)
+	}
+	if got := lines[mergedPath]; len(got) != 4 || got[3] != "merged" {
+		t.Errorf("unexpected status for merged worktree: %v", got)
+	}
+	if got := lines[ffPath]; len(got) != 4 || got[3] != "merged" {
+		t.Errorf("unexpected status for fast-forwarded worktree: %v", got)
+	}
+	if got := lines[aheadPath]; len(got) != 4 || got[1] != "clean" || got[2] != "+1/-0" || got[3] != "-" {
+		t.Errorf("unexpected status for ahead worktree: %v", got)
+	}
+}
+
+func TestList_Status_JSON(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/status-json")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+	repo.CommitFile(wtPath, "ahead.txt", "ahead")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "list", "--status", "--json")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+
+	var entries []struct {
+		Path   string `json:"path"`
+		Status *struct {
+			Dirty    bool   `json:"dirty"`
+			Upstream string `json:"upstream"`
+			Ahead    int    `json:"ahead"`
+			Merged   bool   `json:"merged"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil {
+		t.Fatalf("failed to parse JSON output: %v\n%s", err, stdout)
+	}
+
+	for _, e := range entries {
+		if e.Path != wtPath {
+			continue
+		}
+		if e.Status == nil {
+			t.Fatal("expected status to be present")
+		}
+		if e.Status.Dirty || e.Status.Merged || e.Status.Ahead != 1 || e.Status.Upstream != "origin/main" {
+			t.Errorf("unexpected status: %+v", *e.Status)
+		}
+		return
+	}
+	t.Errorf("worktree %q not found in output: %s", wtPath, stdout)
+}
+
+func TestList_JSON_NoStatusByDefault(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, _, exitCode := runGw(t, repo.Root, "list", "--json")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if strings.Contains(stdout, `"status"`) {
+		t.Errorf("expected no status without --status, got: %s", stdout)
+	}
+}
+
+func TestList_Status_Format(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "list", "--status", "--format", "{{.Branch}} {{.Status.Dirty}}")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != "main false" {
+		t.Errorf("got %q, want %q", strings.TrimSpace(stdout), "main false")
+	}
+}
+
+func TestList_Status_OriginHeadNotSet(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.DeleteOriginHead()
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "list", "--status")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, repo.Root) {
+		t.Errorf("expected list to contain repo root %q, got: %q", repo.Root, stdout)
+	}
+	if !strings.Contains(stderr, "cannot determine merge target") {
+		t.Errorf("expected merge target warning in stderr, got: %q", stderr)
+	}
+}
+
+// --- gw rm ---
+
+func TestRm_Basic(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/rm-test")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Remove it
+	stdout, _, exitCode := runGw(t, repo.Root, "rm", wtPath)
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+
+	// stdout should be empty
+	if strings.TrimSpace(stdout) != "" {
+		t.Errorf("expected empty stdout, got: %q", stdout)
+	}
+
+	// Worktree directory should be gone
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	}
+}
+
+func TestRm_UncommittedChanges_WithoutForce(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/dirty-no-force")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Create uncommitted changes in the worktree
+	if err := os.WriteFile(filepath.Join(wtPath, "dirty.txt"), []byte("dirty"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitAdd := exec.Command("git", "add", "dirty.txt")
+	gitAdd.Dir = wtPath
+	if err := gitAdd.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove without --force should fail
+	_, _, exitCode = runGw(t, repo.Root, "rm", wtPath)
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+
+	// Worktree should still exist
+	if _, err := os.Stat(wtPath); os.IsNotExist(err) {
+		t.Error("worktree should not have been removed without --force")
+	}
+}
+
+func TestRm_Force_UncommittedChanges(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/force-rm")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Create uncommitted changes in the worktree
+	if err := os.WriteFile(filepath.Join(wtPath, "dirty.txt"), []byte("dirty"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitAdd := exec.Command("git", "add", "dirty.txt")
+	gitAdd.Dir = wtPath
+	if err := gitAdd.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove with --force
+	_, _, exitCode = runGw(t, repo.Root, "rm", wtPath, "--force")
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+
+	// Worktree directory should be gone
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	}
+}
+
+func TestRm_PreRemoveHook_Failure_NoForce(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/hook-rm")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Set up failing pre-remove hook
+	repo.WriteHook("pre-remove", "#!/bin/sh\nexit 1\n")
+
+	// Try to remove
+	_, _, exitCode = runGw(t, repo.Root, "rm", wtPath)
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+
+	// Worktree should still exist
+	if _, err := os.Stat(wtPath); os.IsNotExist(err) {
+		t.Error("worktree should not have been removed when pre-remove hook fails without --force")
+	}
+}
+
+func TestRm_PreRemoveHook_Failure_WithForce(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/hook-force-rm")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Set up failing pre-remove hook
+	repo.WriteHook("pre-remove", "#!/bin/sh\nexit 1\n")
+
+	// Remove with --force
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", wtPath, "--force")
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+
+	if !strings.Contains(stderr, "gw: warning:") {
+		t.Errorf("expected warning in stderr, got: %q", stderr)
+	}
+
+	// Worktree should be gone
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	}
+}
+
+func TestRm_PostRemoveHook_Failure(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/post-rm")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Set up failing post-remove hook
+	repo.WriteHook("post-remove", "#!/bin/sh\nexit 1\n")
+
+	// Remove
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", wtPath)
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+
+	if !strings.Contains(stderr, "gw: warning:") {
+		t.Errorf("expected warning in stderr, got: %q", stderr)
+	}
+
+	// Worktree should be gone
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	}
+}
+
+func TestRm_NotFound(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a subdirectory that is inside the repo but is not a worktree
+	subdir := filepath.Join(repo.Root, "subdir")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", subdir)
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "not a git worktree") {
+		t.Errorf("expected 'not a git worktree' in stderr, got: %q", stderr)
+	}
+}
+
+func TestRm_BranchSurvives(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/branch-survives")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Remove it
+	_, _, exitCode = runGw(t, repo.Root, "rm", wtPath)
+	if exitCode != 0 {
+		t.Fatalf("gw rm exit code = %d, want 0", exitCode)
+	}
+
+	// Branch should still exist
+	gitCmd := exec.Command("git", "rev-parse", "--verify", "feature/branch-survives")
+	gitCmd.Dir = repo.Root
+	if err := gitCmd.Run(); err != nil {
+		t.Error("branch should still exist after worktree removal")
+	}
+}
+
+func TestRm_EmptyStdout(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/empty-stdout")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Remove it
+	stdout, _, exitCode := runGw(t, repo.Root, "rm", wtPath)
+	if exitCode != 0 {
+		t.Fatalf("gw rm exit code = %d, want 0", exitCode)
+	}
+
+	if stdout != "" {
+		t.Errorf("expected empty stdout, got: %q", stdout)
+	}
+}
+
+func TestRm_NoArgs(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "path required") {
+		t.Errorf("expected 'path required' in stderr, got: %q", stderr)
+	}
+}
+
+func TestRm_MainWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", repo.Root)
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "cannot remove the main worktree") {
+		t.Errorf("expected 'cannot remove the main worktree' in stderr, got: %q", stderr)
+	}
+}
+
+func TestRm_OutsideGitRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	_, _, exitCode := runGw(t, tmpDir, "rm", "/nonexistent/path")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+}
+
+func TestRm_ExtraArgs(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "feature/foo", "extra")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "unexpected argument") {
+		t.Errorf("expected 'unexpected argument' in stderr, got: %q", stderr)
+	}
+}
+
+func TestRm_ForceFlagBeforePath(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/flag-order")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Remove with --force before path
+	_, _, exitCode = runGw(t, repo.Root, "rm", "--force", wtPath)
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+
+	// Worktree directory should be gone
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	}
+}
+
+func TestRm_FromWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create two worktrees
+	addStdout1, _, exitCode := runGw(t, repo.Root, "add", "feature/rm-source")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	sourcePath := strings.TrimSpace(addStdout1)
+
+	addStdout2, _, exitCode := runGw(t, repo.Root, "add", "feature/rm-target")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	targetPath := strings.TrimSpace(addStdout2)
+
+	// Remove the target from inside the source worktree
+	_, _, exitCode = runGw(t, sourcePath, "rm", targetPath)
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+
+	// Target worktree should be gone
+	if _, err := os.Stat(targetPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", targetPath)
+	}
+}
+
+func TestRm_RelativePath(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/rel-path")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Compute relative path from repo root to the worktree
+	relPath, err := filepath.Rel(repo.Root, wtPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove using relative path
+	_, _, exitCode = runGw(t, repo.Root, "rm", relPath)
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+
+	// Worktree directory should be gone
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	}
+}
+
+func TestRm_StaleWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	// Create a worktree
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/stale")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// Manually delete the worktree directory to simulate a stale entry
+	if err := os.RemoveAll(wtPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove the stale worktree with --force from repo root
+	_, _, exitCode = runGw(t, repo.Root, "rm", "--force", wtPath)
+
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+}
+
+func TestRm_ForceOnly_NoPath(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--force")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "path required") {
+		t.Errorf("expected 'path required' in stderr, got: %q", stderr)
+	}
+}
+
+func TestRm_ByBranch_AutoDetect(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/rm-by-branch")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "rm", "feature/rm-by-branch")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != "" {
+		t.Errorf("expected empty stdout, got: %q", stdout)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	}
+}
+
+func TestRm_ByBranch_Flag(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/rm-branch-flag")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--branch", "feature/rm-branch-flag")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	}
+}
+
+func TestRm_ByBranch_HookReceivesBranch(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	outFile := filepath.Join(t.TempDir(), "branch.txt")
+	repo.WriteHook("pre-remove", "#!/bin/sh\necho \"$GW_BRANCH\" > "+outFile+"\n")
+
+	if _, _, exitCode := runGw(t, repo.Root, "add", "feature/rm-hook-branch"); exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--branch", "feature/rm-hook-branch")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "feature/rm-hook-branch" {
+		t.Errorf("GW_BRANCH = %q, want %q", got, "feature/rm-hook-branch")
+	}
+}
+
+func TestRm_ByBranch_NotCheckedOut(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateBranch("no-worktree")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--branch", "no-worktree")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "not checked out in any worktree") {
+		t.Errorf("expected 'not checked out in any worktree' in stderr, got: %q", stderr)
+	}
+}
+
+func TestRm_ByBranch_MainWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--branch", "main")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "cannot remove the main worktree") {
+		t.Errorf("expected main worktree error, got: %q", stderr)
+	}
+}
+
+func TestRm_UnknownTarget(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "does-not-exist")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "not a git worktree") {
+		t.Errorf("expected 'not a git worktree' in stderr, got: %q", stderr)
+	}
+}
+
+func TestRm_AmbiguousPathAndBranch(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "ambiguous")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// A directory named like the branch in the current directory
+	if err := os.MkdirAll(filepath.Join(repo.Root, "ambiguous"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "ambiguous")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "ambiguous") || !strings.Contains(stderr, "--branch") {
+		t.Errorf("expected ambiguity error mentioning --branch, got: %q", stderr)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Errorf("worktree should not have been removed: %v", err)
+	}
+
+	// --branch resolves the ambiguity
+	_, stderr, exitCode = runGw(t, repo.Root, "rm", "--branch", "ambiguous")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	}
+}
+
+func TestRm_PathMatchingOwnBranch(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig(`worktrees_dir = "trees"`)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "same")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	// From the base dir, "same" is both the worktree path and its own branch
+	_, stderr, exitCode := runGw(t, filepath.Dir(wtPath), "rm", "same")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+}
+
+func TestRm_DeleteBranch_Merged(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/delete-merged")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "rm", "--delete-branch", wtPath)
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != "" {
+		t.Errorf("expected empty stdout, got: %q", stdout)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	}
+
+	branchOut, _ := exec.Command("git", "-C", repo.Root, "branch", "--list", "feature/delete-merged").Output()
+	if strings.TrimSpace(string(branchOut)) != "" {
+		t.Errorf("branch should have been deleted, got: %q", string(branchOut))
+	}
+}
+
+func TestRm_DeleteBranch_Unmerged_Refused(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/delete-unmerged")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+	repo.CommitFile(wtPath, "work.txt", "work")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--delete-branch", wtPath)
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "not merged") {
+		t.Errorf("expected 'not merged' in stderr, got: %q", stderr)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Errorf("worktree should not have been removed: %v", err)
+	}
+}
+
+func TestRm_DeleteBranch_Unmerged_PreRemoveNotExecuted(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	marker := filepath.Join(t.TempDir(), "pre-remove-ran")
+	repo.WriteHook("pre-remove", "#!/bin/sh\ntouch "+marker+"\n")
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/delete-no-hook")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+	repo.CommitFile(wtPath, "work.txt", "work")
+
+	if _, _, exitCode := runGw(t, repo.Root, "rm", "--delete-branch", wtPath); exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("pre-remove hook should not run when branch deletion is refused")
+	}
+}
+
+func TestRm_DeleteBranch_Unmerged_Force(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/delete-force")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+	repo.CommitFile(wtPath, "work.txt", "work")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--delete-branch", "--force", wtPath)
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	branchOut, _ := exec.Command("git", "-C", repo.Root, "branch", "--list", "feature/delete-force").Output()
+	if strings.TrimSpace(string(branchOut)) != "" {
+		t.Errorf("branch should have been deleted, got: %q", string(branchOut))
+	}
+}
+
+func TestRm_DeleteBranch_AheadOfUpstream_Refused(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("ahead-wt", "ahead-branch")
+	repo.PushBranch("ahead-branch")
+	repo.SetUpstream("ahead-branch", "origin/ahead-branch")
+	repo.CommitFile(wtPath, "work.txt", "work")
+	// Merged into origin/main, but the branch's own upstream lacks the commit
+	repo.MergeBranch("ahead-branch")
+	repo.PushBranch("main")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--delete-branch", wtPath)
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "not on its upstream") {
+		t.Errorf("expected upstream error in stderr, got: %q", stderr)
+	}
+}
+
+func TestRm_DeleteBranch_BeforePostRemove(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	outFile := filepath.Join(t.TempDir(), "branch-state.txt")
+	repo.WriteHook("post-remove", "#!/bin/sh\n"+
+		"if git show-ref --verify --quiet \"refs/heads/$GW_BRANCH\"; then echo exists; else echo deleted; fi > "+outFile+"\n")
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/delete-hook")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "rm", "--delete-branch", wtPath); exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "deleted" {
+		t.Errorf("post-remove hook saw branch as %q, want %q", got, "deleted")
+	}
+}
+
+func TestRm_DeleteBranch_Detached(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateDetachedWorktree("delete-detached")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--delete-branch", wtPath)
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "no branch") {
+		t.Errorf("expected 'no branch' in stderr, got: %q", stderr)
+	}
+}
+
+func TestRm_HookEnvironment(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	logFile := filepath.Join(t.TempDir(), "env.log")
+	script := "#!/bin/sh\necho \"$GW_HOOK_NAME force=$GW_FORCE sha=$GW_HEAD_SHA name=$GW_SANITIZED_NAME\" >> " + logFile + "\n"
+	repo.WriteHook("pre-remove", script)
+	repo.WriteHook("post-remove", script)
+	repo.CreateWorktreeInBaseDir("rm/env-a")
+	repo.CreateWorktreeInBaseDir("rm/env-b")
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "rm", "rm/env-a"); exitCode != 0 {
+		t.Fatalf("exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	if _, stderr, exitCode := runGw(t, repo.Root, "rm", "--force", "rm/env-b"); exitCode != 0 {
+		t.Fatalf("exit code = %d; stderr: %s", exitCode, stderr)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha := repo.HeadSHA("main")
+	want := strings.Join([]string{
+		"pre-remove force=false sha=" + sha + " name=rm-env-a",
+		"post-remove force=false sha=" + sha + " name=rm-env-a",
+		"pre-remove force=true sha=" + sha + " name=rm-env-b",
+		"post-remove force=true sha=" + sha + " name=rm-env-b",
+	}, "\n")
+	if got := strings.TrimSpace(string(data)); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// --- gw prune ---
+
+// setupPruneRepo creates worktrees in each state relevant to gw prune and returns their paths.
+func setupPruneRepo(t *testing.T, repo *testutil.TestRepo) (merged, unmerged, gone string) {
+	t.Helper()
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "prune-merged")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	merged = strings.TrimSpace(addStdout)
+	mergeWorktree(repo, merged, "prune-merged")
+
+	addStdout, _, exitCode = runGw(t, repo.Root, "add", "prune-unmerged")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	unmerged = strings.TrimSpace(addStdout)
+	repo.CommitFile(unmerged, "work.txt", "work")
+
+	gone = repo.CreateWorktree("prune-gone-wt", "prune-gone")
+	repo.CommitFile(gone, "work.txt", "work")
+	repo.PushBranch("prune-gone")
+	repo.SetUpstream("prune-gone", "origin/prune-gone")
+	repo.DeleteRemoteRef("origin/prune-gone")
+
+	return merged, unmerged, gone
+}
+
+func TestPrune_DryRun(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	merged, unmerged, gone := setupPruneRepo(t, repo)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "prune", "--dry-run")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, merged+"\n") || !strings.Contains(stdout, gone+"\n") {
+		t.Errorf("expected merged and gone worktrees in output, got: %q", stdout)
+	}
+	if strings.Contains(stdout, unmerged) || strings.Contains(stdout, repo.Root+"\n") {
+		t.Errorf("expected unmerged and main worktrees not in output, got: %q", stdout)
+	}
+	for _, p := range []string{merged, unmerged, gone} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("worktree should not have been removed in dry-run: %v", err)
+		}
+	}
+}
+
+func TestPrune_KeepsFreshWorktrees(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "fresh-feature")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	fresh := strings.TrimSpace(addStdout)
+
+	// A branch without commits of its own is an ancestor of origin/main but has not been merged.
+	stdout, stderr, exitCode := runGw(t, repo.Root, "prune")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != "" {
+		t.Errorf("expected no removals, got: %q", stdout)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("fresh worktree should not have been removed: %v", err)
+	}
+}
+
+func TestPrune_FastForwardMerged(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "fresh-feature")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	fresh := strings.TrimSpace(addStdout)
+	addStdout, _, exitCode = runGw(t, repo.Root, "add", "ff-feature")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	ff := strings.TrimSpace(addStdout)
+	repo.CommitFile(ff, "work.txt", "work")
+	repo.MergeBranch("ff-feature")
+	repo.PushBranch("main")
+
+	// Both are on origin/main's first-parent history; only ff-feature has commits of its own.
+	stdout, stderr, exitCode := runGw(t, repo.Root, "prune", "--merged", "--dry-run")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != ff {
+		t.Errorf("got %q, want only %q (fresh %q excluded)", stdout, ff, fresh)
+	}
+}
+
+func TestPrune_RemovesWithHooks(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	logFile := filepath.Join(t.TempDir(), "hooks.log")
+	repo.WriteHook("pre-remove", "#!/bin/sh\necho \"pre $GW_BRANCH\" >> "+logFile+"\n")
+	repo.WriteHook("post-remove", "#!/bin/sh\necho \"post $GW_BRANCH\" >> "+logFile+"\n")
+	merged, unmerged, gone := setupPruneRepo(t, repo)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "prune")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	for _, p := range []string{merged, gone} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("worktree should have been removed: %s", p)
+		}
+		if !strings.Contains(stdout, p) {
+			t.Errorf("expected removed path %q in stdout, got: %q", p, stdout)
+		}
+	}
+	if _, err := os.Stat(unmerged); err != nil {
+		t.Errorf("unmerged worktree should not have been removed: %v", err)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"pre prune-merged", "post prune-merged", "pre prune-gone", "post prune-gone"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in hook log, got: %q", want, string(data))
+		}
+	}
+}
+
+func TestPrune_GoneOnly(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	merged, _, gone := setupPruneRepo(t, repo)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "prune", "--gone", "--dry-run")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != gone {
+		t.Errorf("got %q, want only %q (merged %q excluded)", stdout, gone, merged)
+	}
+}
+
+func TestPrune_MergedOnly(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	merged, _, _ := setupPruneRepo(t, repo)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "prune", "--merged", "--dry-run")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != merged {
+		t.Errorf("got %q, want only %q", stdout, merged)
+	}
+}
+
+func TestPrune_OlderThan(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	setupPruneRepo(t, repo)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "prune", "--older-than", "30d", "--dry-run")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != "" {
+		t.Errorf("expected no candidates for fresh commits, got: %q", stdout)
+	}
+
+	stdout, _, exitCode = runGw(t, repo.Root, "prune", "--older-than", "0s", "--dry-run")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if len(strings.Split(strings.TrimSpace(stdout), "\n")) != 2 {
+		t.Errorf("expected 2 candidates with --older-than 0s, got: %q", stdout)
+	}
+}
+
+func TestPrune_InvalidDuration(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "prune", "--older-than", "soon")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "invalid duration") {
+		t.Errorf("expected 'invalid duration' in stderr, got: %q", stderr)
+	}
+}
+
+func TestPrune_MissingDirectory(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "prune-missing")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+	repo.CommitFile(wtPath, "work.txt", "work")
+	if err := os.RemoveAll(wtPath); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "prune")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != wtPath {
+		t.Errorf("got %q, want %q", stdout, wtPath)
+	}
+
+	listOut, _, _ := runGw(t, repo.Root, "list")
+	if strings.Contains(listOut, wtPath) {
+		t.Errorf("pruned worktree should no longer be listed, got: %q", listOut)
+	}
+}
+
+func TestPrune_SkipsDirty(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "prune-dirty")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+	mergeWorktree(repo, wtPath, "prune-dirty")
+	if err := os.WriteFile(filepath.Join(wtPath, "dirty.txt"), []byte("dirty"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "prune")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != "" {
+		t.Errorf("expected no removals, got: %q", stdout)
+	}
+	if !strings.Contains(stderr, "uncommitted changes") {
+		t.Errorf("expected skip warning in stderr, got: %q", stderr)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Errorf("dirty worktree should not have been removed: %v", err)
+	}
+}
+
+func TestPrune_PreRemoveHookFailure(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("pre-remove", "#!/bin/sh\nexit 1\n")
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "prune-hook-fail")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+	mergeWorktree(repo, wtPath, "prune-hook-fail")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "prune")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "pre-remove hook failed") {
+		t.Errorf("expected hook failure in stderr, got: %q", stderr)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Errorf("worktree should not have been removed: %v", err)
+	}
+}
+
+func TestPrune_ExtraArgs(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "prune", "extra")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "unexpected argument") {
+		t.Errorf("expected 'unexpected argument' in stderr, got: %q", stderr)
+	}
+}
+
+// --- gw path / gw cd / gw shell-init ---
+
+func TestPath_Branch(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/path")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "path", "feature/path")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if stdout != wtPath+"\n" {
+		t.Errorf("got %q, want %q", stdout, wtPath+"\n")
+	}
+	if stderr != "" {
+		t.Errorf("expected empty stderr, got: %q", stderr)
+	}
+}
+
+func TestPath_MainWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("path-from-wt", "path-from-wt")
+
+	stdout, _, exitCode := runGw(t, wtPath, "path", "main")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if strings.TrimSpace(stdout) != repo.Root {
+		t.Errorf("got %q, want %q", strings.TrimSpace(stdout), repo.Root)
+	}
+}
+
+func TestPath_NotCheckedOut_ComputedPath(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "path", "feature/not-yet")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	want := filepath.Join(filepath.Dir(repo.Root), filepath.Base(repo.Root)+"-worktrees", "feature-not-yet")
+	if strings.TrimSpace(stdout) != want {
+		t.Errorf("got %q, want %q", strings.TrimSpace(stdout), want)
+	}
+	if _, err := os.Stat(want); !os.IsNotExist(err) {
+		t.Errorf("gw path should not create anything: %s", want)
+	}
+
+	// gw add creates the worktree exactly there
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/not-yet")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	if strings.TrimSpace(addStdout) != want {
+		t.Errorf("gw add path = %q, gw path = %q", strings.TrimSpace(addStdout), want)
+	}
+}
+
+func TestPath_ComputedPath_WorktreesDir(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig(`worktrees_dir = "/tmp/gw-path-test"`)
+
+	stdout, _, exitCode := runGw(t, repo.Root, "path", "feature/x")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if strings.TrimSpace(stdout) != "/tmp/gw-path-test/feature-x" {
+		t.Errorf("got %q, want %q", strings.TrimSpace(stdout), "/tmp/gw-path-test/feature-x")
+	}
+}
+
+func TestPath_RegisteredPathWinsOverConfig(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/moved-config")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+	repo.WriteConfig(`worktrees_dir = "elsewhere"`)
+
+	stdout, _, exitCode := runGw(t, repo.Root, "path", "feature/moved-config")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if strings.TrimSpace(stdout) != wtPath {
+		t.Errorf("got %q, want registered path %q", strings.TrimSpace(stdout), wtPath)
+	}
+}
+
+func TestPath_Existing_NoWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "path", "--existing", "feature/none")
+
+	if exitCode != 2 {
+		t.Errorf("exit code = %d, want 2", exitCode)
+	}
+	if stdout != "" {
+		t.Errorf("expected empty stdout, got: %q", stdout)
+	}
+	if !strings.Contains(stderr, "no worktree") {
+		t.Errorf("expected 'no worktree' in stderr, got: %q", stderr)
+	}
+}
+
+func TestPath_Existing_WithWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, _, exitCode := runGw(t, repo.Root, "path", "--existing", "main")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if strings.TrimSpace(stdout) != repo.Root {
+		t.Errorf("got %q, want %q", strings.TrimSpace(stdout), repo.Root)
+	}
+}
+
+func TestPath_InvalidBranchName(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "path", "/")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "invalid branch name") {
+		t.Errorf("expected 'invalid branch name' in stderr, got: %q", stderr)
+	}
+}
+
+func TestPath_NoArgs(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "path")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "branch name required") {
+		t.Errorf("expected 'branch name required' in stderr, got: %q", stderr)
+	}
+}
+
+func TestPath_ExtraArgs(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "path", "main", "extra")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "unexpected argument") {
+		t.Errorf("expected 'unexpected argument' in stderr, got: %q", stderr)
+	}
+}
+
+func TestCd_WithoutShellIntegration(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "cd", "main")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != repo.Root {
+		t.Errorf("got %q, want %q", strings.TrimSpace(stdout), repo.Root)
+	}
+	if !strings.Contains(stderr, "shell-init") {
+		t.Errorf("expected shell integration warning in stderr, got: %q", stderr)
+	}
+}
+
+func TestCd_NoWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, _, exitCode := runGw(t, repo.Root, "cd", "feature/none")
+
+	if exitCode != 2 {
+		t.Errorf("exit code = %d, want 2", exitCode)
+	}
+	if stdout != "" {
+		t.Errorf("expected empty stdout, got: %q", stdout)
+	}
+}
+
+func TestShellInit_Shells(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{"bash", "gw() {"},
+		{"zsh", "gw() {"},
+		{"fish", "function gw"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			stdout, stderr, exitCode := runGw(t, t.TempDir(), "shell-init", tt.shell)
+
+			if exitCode != 0 {
+				t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+			}
+			if !strings.Contains(stdout, tt.want) {
+				t.Errorf("expected %q in output, got: %q", tt.want, stdout)
+			}
+		})
+	}
+}
+
+func TestShellInit_UnsupportedShell(t *testing.T) {
+	_, stderr, exitCode := runGw(t, t.TempDir(), "shell-init", "tcsh")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "unsupported shell") {
+		t.Errorf("expected 'unsupported shell' in stderr, got: %q", stderr)
+	}
+}
+
+func TestShellInit_NoArgs(t *testing.T) {
+	_, stderr, exitCode := runGw(t, t.TempDir(), "shell-init")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "shell name required") {
+		t.Errorf("expected 'shell name required' in stderr, got: %q", stderr)
+	}
+}
+
+// runShell evaluates "gw shell-init bash" in a bash process and then runs script.
+func runShell(t *testing.T, dir, script string) (stdout, stderr string) {
+	t.Helper()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+
+	cmd := exec.Command("bash", "-c", `eval "$(gw shell-init bash)"`+"\n"+script)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "PATH="+filepath.Dir(gwBinary)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("bash failed: %v\nstderr: %s", err, errBuf.String())
+	}
+	return outBuf.String(), errBuf.String()
+}
+
+func TestShellInit_Bash_Cd(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("shell-cd-wt", "shell-cd")
+
+	stdout, stderr := runShell(t, repo.Root, "gw cd shell-cd && pwd -P")
+
+	if strings.TrimSpace(stdout) != wtPath {
+		t.Errorf("pwd = %q, want %q", strings.TrimSpace(stdout), wtPath)
+	}
+	if strings.Contains(stderr, "warning") {
+		t.Errorf("expected no warning under shell integration, got: %q", stderr)
+	}
+}
+
+func TestShellInit_Bash_AddCd(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, stderr := runShell(t, repo.Root, "gw add --cd feature/shell-add && pwd -P")
+
+	if !strings.HasSuffix(strings.TrimSpace(stdout), "feature-shell-add") {
+		t.Errorf("expected to be in new worktree, pwd = %q; stderr: %s", stdout, stderr)
+	}
+}
+
+func TestShellInit_Bash_Passthrough(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, _ := runShell(t, repo.Root, "gw list && pwd -P")
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || lines[0] != repo.Root || lines[1] != repo.Root {
+		t.Errorf("unexpected output: %q", stdout)
+	}
+}
+
+func TestShellInit_Bash_Completion(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateWorktree("shell-comp-wt", "shell-comp")
+
+	stdout, _ := runShell(t, repo.Root, "gw cd --generate-shell-completion && pwd -P")
+
+	if !strings.Contains(stdout, "shell-comp\n") {
+		t.Errorf("expected branch completion, got: %q", stdout)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(stdout), repo.Root) {
+		t.Errorf("completion should not change directory, got: %q", stdout)
+	}
+}
+
+// --- path templates ---
+
+func TestPathTemplate_NestedBranch(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("worktrees_dir = \"trees\"\npath_template = \"{{.Branch}}\"\n")
+	baseDir := filepath.Join(repo.Root, "trees")
+
+	addStdout, stderr, exitCode := runGw(t, repo.Root, "add", "feature/nested")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+	if want := filepath.Join(baseDir, "feature", "nested"); wtPath != want {
+		t.Errorf("got path %q, want %q", wtPath, want)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Errorf("worktree should exist: %v", err)
+	}
+
+	pathOut, _, _ := runGw(t, repo.Root, "path", "feature/not-yet")
+	if want := filepath.Join(baseDir, "feature", "not-yet"); strings.TrimSpace(pathOut) != want {
+		t.Errorf("gw path = %q, want %q", strings.TrimSpace(pathOut), want)
+	}
+
+	mvStdout, stderr, exitCode := runGw(t, repo.Root, "mv", "feature/nested", "bugfix/renamed")
+	if exitCode != 0 {
+		t.Fatalf("gw mv exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if want := filepath.Join(baseDir, "bugfix", "renamed"); strings.TrimSpace(mvStdout) != want {
+		t.Errorf("gw mv path = %q, want %q", strings.TrimSpace(mvStdout), want)
+	}
+}
+
+func TestPathTemplate_Variables(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("worktrees_dir = \"trees\"\npath_template = \"{{.RepoName}}/{{index .BranchSegments 0}}/{{.Branch | sanitize}}\"\n")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "path", "feature/x")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	want := filepath.Join(repo.Root, "trees", filepath.Base(repo.Root), "feature", "feature-x")
+	if strings.TrimSpace(stdout) != want {
+		t.Errorf("got %q, want %q", strings.TrimSpace(stdout), want)
+	}
+}
+
+func TestPathTemplate_EscapingPathRejected(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig(`path_template = "../../{{.Branch}}"`)
+	marker := filepath.Join(t.TempDir(), "pre-add-ran")
+	repo.WriteHook("pre-add", "#!/bin/sh\ntouch "+marker+"\n")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feature/escape")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "which is not a path inside") {
+		t.Errorf("expected path_template error in stderr, got: %q", stderr)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("pre-add hook should not run when the path cannot be computed")
+	}
+	branchOut, _ := exec.Command("git", "-C", repo.Root, "branch", "--list", "feature/escape").Output()
+	if len(branchOut) != 0 {
+		t.Errorf("branch should not have been created, got %q", branchOut)
+	}
+}
+
+func TestPathTemplate_InvalidTemplate(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig(`path_template = "{{.Unknown}}"`)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "path", "feature/x")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "path_template") {
+		t.Errorf("expected path_template error in stderr, got: %q", stderr)
+	}
+}
+
+// --- branch name sanitization ---
+
+func TestSanitize_CollisionNamesOwner(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "feat/a-b"); exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feat-a/b")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, `used by the worktree of branch "feat/a-b"`) {
+		t.Errorf("expected the owning branch in stderr, got: %q", stderr)
+	}
+}
+
+func TestSanitize_HashSuffix(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("[sanitize]\nstrategy = \"hash-suffix\"\n")
+
+	stdout1, stderr, exitCode := runGw(t, repo.Root, "add", "feat/a-b")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	stdout2, stderr, exitCode := runGw(t, repo.Root, "add", "feat-a/b")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+
+	path1, path2 := strings.TrimSpace(stdout1), strings.TrimSpace(stdout2)
+	if path1 == path2 {
+		t.Errorf("both branches got %q", path1)
+	}
+	if !strings.HasPrefix(filepath.Base(path1), "feat-a-b-") {
+		t.Errorf("got path %q, want feat-a-b-<hash>", path1)
+	}
+}
+
+func TestSanitize_NestedAndSlug(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	baseDir := filepath.Join(filepath.Dir(repo.Root), filepath.Base(repo.Root)+"-worktrees")
+
+	repo.WriteConfig("[sanitize]\nstrategy = \"nested\"\n")
+	stdout, _, _ := runGw(t, repo.Root, "path", "feature/x")
+	if want := filepath.Join(baseDir, "feature", "x"); strings.TrimSpace(stdout) != want {
+		t.Errorf("nested: got %q, want %q", strings.TrimSpace(stdout), want)
+	}
+
+	repo.WriteConfig("[sanitize]\nstrategy = \"slug\"\nmax_length = 12\n")
+	stdout, _, _ = runGw(t, repo.Root, "path", "Feature/Fix_Login-Page")
+	if want := filepath.Join(baseDir, "feature-fix"); strings.TrimSpace(stdout) != want {
+		t.Errorf("slug: got %q, want %q", strings.TrimSpace(stdout), want)
+	}
+}
+
+func TestSanitize_HookSanitizedName(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("[sanitize]\nstrategy = \"slug\"\n")
+	logFile := filepath.Join(t.TempDir(), "hook.log")
+	repo.WriteHook("post-add", "#!/bin/sh\necho \"$GW_SANITIZED_NAME\" > "+logFile+"\n")
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "Feature/X"); exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(data)) != "feature-x" {
+		t.Errorf("GW_SANITIZED_NAME = %q, want %q", strings.TrimSpace(string(data)), "feature-x")
+	}
+}
+
+func TestSanitize_InvalidStrategy(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("[sanitize]\nstrategy = \"lowercase\"\n")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feature/x")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, `unknown sanitize.strategy "lowercase"`) {
+		t.Errorf("expected strategy error in stderr, got: %q", stderr)
+	}
+}
+
+// --- path collisions ---
+
+func TestOnCollision_Suffix(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig(`on_collision = "suffix"`)
+
+	first, stderr, exitCode := runGw(t, repo.Root, "add", "feat/a-b")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	firstPath := strings.TrimSpace(first)
+
+	second, stderr, exitCode := runGw(t, repo.Root, "add", "feat-a/b")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	secondPath := strings.TrimSpace(second)
+	if secondPath != firstPath+"-2" {
+		t.Errorf("got path %q, want %q", secondPath, firstPath+"-2")
+	}
+	if !strings.Contains(stderr, `used by the worktree of branch "feat/a-b"`) {
+		t.Errorf("expected the occupying branch in stderr, got: %q", stderr)
+	}
+
+	// A stale directory that is not a worktree is skipped as well
+	stale := filepath.Join(filepath.Dir(firstPath), "stale")
+	if err := os.MkdirAll(filepath.Join(stale, "leftover"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// gw path reports the path gw add will use, without the notice
+	pathOut, stderr, _ := runGw(t, repo.Root, "path", "stale")
+	if strings.TrimSpace(pathOut) != stale+"-2" || stderr != "" {
+		t.Errorf("gw path = %q (stderr %q), want %q", strings.TrimSpace(pathOut), stderr, stale+"-2")
+	}
+	third, stderr, exitCode := runGw(t, repo.Root, "add", "stale")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(third) != stale+"-2" {
+		t.Errorf("got path %q, want %q", strings.TrimSpace(third), stale+"-2")
+	}
+	if !strings.Contains(stderr, stale+" already exists") {
+		t.Errorf("expected notice about the existing directory, got: %q", stderr)
+	}
+
+	pathOut, _, _ = runGw(t, repo.Root, "path", "feat-a/b")
+	if strings.TrimSpace(pathOut) != secondPath {
+		t.Errorf("gw path = %q, want registered path %q", strings.TrimSpace(pathOut), secondPath)
+	}
+}
+
+func TestOnCollision_ReuseIfEmpty(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig(`on_collision = "reuse-if-empty"`)
+	baseDir := filepath.Join(filepath.Dir(repo.Root), filepath.Base(repo.Root)+"-worktrees")
+
+	empty := filepath.Join(baseDir, "feature-empty")
+	if err := os.MkdirAll(empty, 0755); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, exitCode := runGw(t, repo.Root, "add", "feature/empty")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != empty {
+		t.Errorf("got path %q, want %q", strings.TrimSpace(stdout), empty)
+	}
+	if _, err := os.Stat(filepath.Join(empty, ".gitkeep")); err != nil {
+		t.Errorf("worktree should be checked out in the reused directory: %v", err)
+	}
+
+	full := filepath.Join(baseDir, "feature-full")
+	if err := os.MkdirAll(filepath.Join(full, "leftover"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, stderr, exitCode = runGw(t, repo.Root, "add", "feature/full")
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "directory already exists") {
+		t.Errorf("expected collision error in stderr, got: %q", stderr)
+	}
+}
+
+func TestOnCollision_Move(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig(`on_collision = "reuse-if-empty"`)
+	baseDir := filepath.Join(filepath.Dir(repo.Root), filepath.Base(repo.Root)+"-worktrees")
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "mv-from"); exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	target := filepath.Join(baseDir, "mv-to")
+	if err := os.MkdirAll(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "mv", "mv-from", "mv-to")
+	if exitCode != 0 {
+		t.Fatalf("gw mv exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != target {
+		t.Errorf("got path %q, want %q", strings.TrimSpace(stdout), target)
+	}
+	if _, err := os.Stat(filepath.Join(target, ".gitkeep")); err != nil {
+		t.Errorf("worktree should have been moved into the reused directory: %v", err)
+	}
+}
+
+// --- per-branch rules / gw config explain ---
+
+func TestRules_AddAppliesMatchingRule(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateBranch("develop")
+	repo.PushBranch("develop")
+	repo.CommitFile(repo.Root, "main-only.txt", "x")
+	repo.PushBranch("main")
+	logFile := filepath.Join(t.TempDir(), "hooks.log")
+	repo.WriteHook("pre-add", "#!/bin/sh\necho \"pre-add $GW_BRANCH\" >> "+logFile+"\n")
+	repo.WriteHook("post-add", "#!/bin/sh\necho \"post-add $GW_BRANCH\" >> "+logFile+"\n")
+	repo.WriteConfig(`
+[[rules]]
+branch = "release/*"
+worktrees_dir = "big"
+
+[[rules]]
+branch_regex = "^(spike|hotfix)/"
+from = "origin/develop"
+disable_hooks = ["post-add"]
+`)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "add", "release/1.0")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if want := filepath.Join(repo.Root, "big", "release-1.0"); strings.TrimSpace(stdout) != want {
+		t.Errorf("release path = %q, want %q", strings.TrimSpace(stdout), want)
+	}
+
+	stdout, stderr, exitCode = runGw(t, repo.Root, "add", "spike/try")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	spikePath := strings.TrimSpace(stdout)
+	if _, err := os.Stat(filepath.Join(spikePath, "main-only.txt")); !os.IsNotExist(err) {
+		t.Error("spike/try should start from origin/develop, not origin/main")
+	}
+
+	// --from still wins over the rule
+	stdout, _, exitCode = runGw(t, repo.Root, "add", "spike/from-main", "--from", "origin/main")
+	if exitCode != 0 {
+		t.Fatalf("gw add --from exit code = %d, want 0", exitCode)
+	}
+	if _, err := os.Stat(filepath.Join(strings.TrimSpace(stdout), "main-only.txt")); err != nil {
+		t.Errorf("--from should override the rule: %v", err)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "pre-add release/1.0\npost-add release/1.0\npre-add spike/try\npre-add spike/from-main\n"
+	if string(data) != want {
+		t.Errorf("hook log = %q, want %q", string(data), want)
+	}
+
+	// A disabled hook can still be run by hand
+	if _, stderr, exitCode := runGw(t, repo.Root, "hook", "run", "post-add", "spike/try"); exitCode != 0 {
+		t.Fatalf("gw hook run exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	data, _ = os.ReadFile(logFile)
+	if !strings.HasSuffix(string(data), "post-add spike/try\n") {
+		t.Errorf("gw hook run should run the disabled hook, log = %q", string(data))
+	}
+}
+
+func TestConfigExplain(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig(`
+[hooks.post-add]
+timeout = "10m"
+
+[[rules]]
+branch = "hotfix/*"
+worktrees_dir = "/tmp/gw-hotfixes"
+disable_hooks = ["post-add"]
+`)
+	configPath := filepath.Join(repo.Root, ".gw", "config")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "config", "explain", "hotfix/urgent")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	ruleName := "rules[0] in " + configPath
+	for _, want := range []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^rule +branch = "hotfix/\*" +` + regexp.QuoteMeta(ruleName) + `$`),
+		regexp.MustCompile(`(?m)^worktrees_dir +/tmp/gw-hotfixes +` + regexp.QuoteMeta(ruleName) + `$`),
+		regexp.MustCompile(`(?m)^from +origin/main +default$`),
+		regexp.MustCompile(`(?m)^hooks\.post-add +disabled, timeout 10m0s +` + regexp.QuoteMeta(ruleName) + `$`),
+		regexp.MustCompile(`(?m)^hooks\.pre-add +enabled +default$`),
+		regexp.MustCompile(`(?m)^path +/tmp/gw-hotfixes/hotfix-urgent$`),
+	} {
+		if !want.MatchString(stdout) {
+			t.Errorf("output does not match %s:\n%s", want, stdout)
+		}
+	}
+
+	stdout, _, exitCode = runGw(t, repo.Root, "config", "explain", "feature/x")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if !regexp.MustCompile(`(?m)^rule +- +no rule matches$`).MatchString(stdout) {
+		t.Errorf("expected no matching rule, got:\n%s", stdout)
+	}
+	if !regexp.MustCompile(`(?m)^hooks\.post-add +enabled, timeout 10m0s +` + regexp.QuoteMeta(configPath) + `$`).MatchString(stdout) {
+		t.Errorf("expected post-add enabled with its timeout source, got:\n%s", stdout)
+	}
+
+	if _, _, exitCode := runGw(t, repo.Root, "config", "explain"); exitCode != 1 {
+		t.Errorf("missing branch: exit code = %d, want 1", exitCode)
+	}
+}
+
+// --- gw config get / set / unset / list / check ---
+
+func TestConfigSetGetUnset(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig(`# shared settings
+worktrees_dir = "../trees" # next to the repository
+`)
+	configPath := filepath.Join(repo.Root, ".gw", "config")
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "config", "set", "worktrees_dir", "../wt"); exitCode != 0 {
+		t.Fatalf("set: exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if _, stderr, exitCode := runGw(t, repo.Root, "config", "set", "hooks.post-add.timeout", "5m"); exitCode != 0 {
+		t.Fatalf("set: exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# shared settings
+worktrees_dir = "../wt" # next to the repository
+
+[hooks.post-add]
+timeout = "5m"
+`
+	if string(data) != want {
+		t.Errorf("config file:\n%s\nwant:\n%s", data, want)
+	}
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "config", "get", "worktrees_dir")
+	if exitCode != 0 {
+		t.Fatalf("get: exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if stdout != "../wt\n" {
+		t.Errorf("get: stdout = %q, want %q", stdout, "../wt\n")
+	}
+
+	stdout, _, _ = runGw(t, repo.Root, "config", "list")
+	for _, want := range []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^worktrees_dir +\.\./wt +` + regexp.QuoteMeta(configPath) + `$`),
+		regexp.MustCompile(`(?m)^hooks\.post-add\.timeout +5m0s +` + regexp.QuoteMeta(configPath) + `$`),
+	} {
+		if !want.MatchString(stdout) {
+			t.Errorf("list output does not match %s:\n%s", want, stdout)
+		}
+	}
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "config", "unset", "worktrees_dir"); exitCode != 0 {
+		t.Fatalf("unset: exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	_, stderr, exitCode = runGw(t, repo.Root, "config", "get", "worktrees_dir")
+	if exitCode != 1 || !strings.Contains(stderr, "worktrees_dir is not set") {
+		t.Errorf("get after unset: exit code = %d, stderr = %q", exitCode, stderr)
+	}
+}
+
+func TestConfigSet_Invalid(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	for _, args := range [][]string{
+		{"worktree_dir", "../wt"},
+		{"on_collision", "bogus"},
+		{"sanitize.max_length", "long"},
+		{"hooks.post-ad.timeout", "5m"},
+		{"trust_all_hooks", "true"},
+		{"hooks.post-add.commands", "make"},
+	} {
+		_, stderr, exitCode := runGw(t, repo.Root, append([]string{"config", "set"}, args...)...)
+		if exitCode != 1 || stderr == "" {
+			t.Errorf("set %v: exit code = %d, stderr = %q; want an error", args, exitCode, stderr)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repo.Root, ".gw", "config")); !os.IsNotExist(err) {
+		t.Errorf("expected no config file to be written, got err = %v", err)
+	}
+}
+
+func TestConfigSet_Global(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "config", "set", "--global", "trust_all_hooks", "true"); exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	data, err := os.ReadFile(filepath.Join(xdg, "gw", "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "trust_all_hooks = true\n" {
+		t.Errorf("global config = %q", data)
+	}
+}
+
+func TestConfigCheck(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "config", "check")
+	if exitCode != 0 || stdout != "" {
+		t.Fatalf("no config: exit code = %d, stdout = %q, stderr = %q", exitCode, stdout, stderr)
+	}
+
+	repo.WriteConfig(`
+worktree_dir = "../wt"
+trust_all_hooks = true
+
+[[rules]]
+branch = "tmp/*"
+worktrees_dir = ".git/tmp"
+disable_hooks = ["post-ad"]
+`)
+	configPath := filepath.Join(repo.Root, ".gw", "config")
+
+	stdout, stderr, exitCode = runGw(t, repo.Root, "config", "check")
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	for _, want := range []string{
+		configPath + `: worktree_dir: unknown key (did you mean "worktrees_dir"?)`,
+		configPath + ": trust_all_hooks: only honored in the global config file; ignored here",
+		configPath + `: rules[0].disable_hooks: unknown hook "post-ad"`,
+		configPath + ": rules[0].worktrees_dir: resolves to " + filepath.Join(repo.Root, ".git", "tmp") + ", inside the repository's .git directory",
+	} {
+		if !strings.Contains(stdout, want+"\n") {
+			t.Errorf("expected %q in output:\n%s", want, stdout)
+		}
+	}
+	if !strings.Contains(stderr, "found 4 problems") {
+		t.Errorf("stderr = %q, want the number of problems", stderr)
+	}
+
+	// Loading stays lenient: the unknown key does not stop other commands.
+	if _, stderr, exitCode := runGw(t, repo.Root, "config", "get", "trust_all_hooks"); exitCode != 1 || !strings.Contains(stderr, "not set") {
+		t.Errorf("get: exit code = %d, stderr = %q", exitCode, stderr)
+	}
+	if _, stderr, exitCode := runGw(t, repo.Root, "path", "main"); exitCode != 0 {
+		t.Errorf("path: exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+}
+
+// --- hook outputs ---
+
+// listOutputs returns the hook outputs shown by "gw list --json", keyed by worktree path.
+func listOutputs(t *testing.T, dir string) map[string]map[string]string {
+	t.Helper()
+	stdout, stderr, exitCode := runGw(t, dir, "list", "--json")
+	if exitCode != 0 {
+		t.Fatalf("gw list exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	var entries []struct {
+		Path    string            `json:"path"`
+		Outputs map[string]string `json:"outputs"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil {
+		t.Fatalf("failed to parse JSON output: %v\n%s", err, stdout)
+	}
+	outputs := map[string]map[string]string{}
+	for _, e := range entries {
+		if e.Outputs != nil {
+			outputs[e.Path] = e.Outputs
+		}
+	}
+	return outputs
+}
+
+func TestHookOutputs_Lifecycle(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	logFile := filepath.Join(t.TempDir(), "hooks.log")
+	repo.WriteHook("post-add", "#!/bin/sh\necho port=3000 >> \"$GW_OUTPUT_FILE\"\necho url=http://localhost:3000 >> \"$GW_OUTPUT_FILE\"\n")
+	repo.WriteHook("pre-remove", "#!/bin/sh\necho \"port=$GW_OUTPUT_PORT\" >> "+logFile+"\ncat | grep -q '\"port\":\"3000\"' && echo event-ok >> "+logFile+"\n")
+
+	addStdout, stderr, exitCode := runGw(t, repo.Root, "add", "feature/outputs")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	got := listOutputs(t, repo.Root)
+	want := map[string]map[string]string{wtPath: {"port": "3000", "url": "http://localhost:3000"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs after add = %v, want %v", got, want)
+	}
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "rm", "feature/outputs"); exitCode != 0 {
+		t.Fatalf("gw rm exit code = %d; stderr: %s", exitCode, stderr)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "port=3000\nevent-ok\n" {
+		t.Errorf("pre-remove did not receive outputs: %q", data)
+	}
+	if got := listOutputs(t, repo.Root); len(got) != 0 {
+		t.Errorf("outputs should be dropped after rm, got %v", got)
+	}
+}
+
+func TestHookOutputs_FollowMove(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("post-add", "#!/bin/sh\necho port=3000 > \"$GW_OUTPUT_FILE\"\n")
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "outputs-old"); exitCode != 0 {
+		t.Fatalf("gw add exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	stdout, stderr, exitCode := runGw(t, repo.Root, "mv", "outputs-old", "outputs-new")
+	if exitCode != 0 {
+		t.Fatalf("gw mv exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	newPath := strings.TrimSpace(stdout)
+
+	got := listOutputs(t, repo.Root)
+	want := map[string]map[string]string{newPath: {"port": "3000"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs after mv = %v, want %v", got, want)
+	}
+}
+
+func TestHookOutputs_ConcurrentHooks(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	started := t.TempDir()
+	// The post-add hooks of several gw processes wait for each other and record their outputs at the same moment.
+	repo.WriteHook("post-add", "#!/bin/sh\n"+
+		"touch \""+started+"/$GW_BRANCH\"\n"+
+		"i=0; while [ $(ls "+started+" | wc -l) -lt 6 ] && [ $i -lt 100 ]; do sleep 0.05; i=$((i+1)); done\n"+
+		"echo \"PORT=$GW_BRANCH\" > \"$GW_OUTPUT_FILE\"\n")
+
+	type run struct {
+		branch         string
+		cmd            *exec.Cmd
+		stdout, stderr bytes.Buffer
+	}
+	var runs []*run
+	for i := range 6 {
+		r := &run{branch: fmt.Sprintf("outputs-%d", i)}
+		r.cmd = exec.Command(gwBinary, "add", r.branch)
+		r.cmd.Dir = repo.Root
+		r.cmd.Stdout = &r.stdout
+		r.cmd.Stderr = &r.stderr
+		if err := r.cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, r)
+		// git itself cannot add worktrees concurrently; start the next gw once this hook runs.
+		for j := 0; j < 100; j++ {
+			if _, err := os.Stat(filepath.Join(started, r.branch)); err == nil {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	want := map[string]map[string]string{}
+	for _, r := range runs {
+		if err := r.cmd.Wait(); err != nil {
+			t.Fatalf("gw add %s: %v; stderr: %s", r.branch, err, r.stderr.String())
+		}
+		want[strings.TrimSpace(r.stdout.String())] = map[string]string{"port": r.branch}
+	}
+
+	// Every update is kept, with keys lowercased.
+	if got := listOutputs(t, repo.Root); !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs = %v, want %v", got, want)
+	}
+}
+
+func TestHookOutputs_InvalidOutputWarns(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("post-add", "#!/bin/sh\necho not-a-pair > \"$GW_OUTPUT_FILE\"\n")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feature/bad-outputs")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "gw: warning: outputs of the post-add hook were not saved") {
+		t.Errorf("expected warning, got stderr: %s", stderr)
+	}
+	if got := listOutputs(t, repo.Root); len(got) != 0 {
+		t.Errorf("expected no outputs, got %v", got)
+	}
+}
+
+// --- inline hook commands ---
+
+func TestInlineHooks_RunInWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	logFile := filepath.Join(t.TempDir(), "hooks.log")
+	repo.WriteHook("post-add", "#!/bin/sh\necho file >> "+logFile+"\n")
+	repo.WriteConfig(`[hooks]
+post-add = ["echo \"command cwd=$(pwd -P) branch=$GW_BRANCH\" >> ` + logFile + `", "false"]
+`)
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "add", "feature/inline")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	wtPath := strings.TrimSpace(stdout)
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "file\ncommand cwd=" + wtPath + " branch=feature/inline\n"
+	if string(data) != want {
+		t.Errorf("hook log = %q, want %q", data, want)
+	}
+	if !strings.Contains(stderr, "gw: warning: post-add hook failed: .gw/config: false") {
+		t.Errorf("expected warning naming the failed command, got: %q", stderr)
+	}
+}
+
+func TestInlineHooks_PreAddFailureAborts(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("[hooks.pre-add]\ncommands = [\"exit 1\"]\n")
+
+	_, _, exitCode := runGw(t, repo.Root, "add", "feature/inline-abort")
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if _, _, exitCode := runGw(t, repo.Root, "path", "--existing", "feature/inline-abort"); exitCode == 0 {
+		t.Error("worktree should not be created")
+	}
+}
+
+func TestInlineHooks_TrustedThroughConfig(t *testing.T) {
+	enableHookTrust(t)
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("[hooks]\npre-add = [\"true\"]\n")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feature/inline-untrusted")
+	if exitCode != 1 || !strings.Contains(stderr, `hook ".gw/config: true" is not trusted`) {
+		t.Fatalf("expected untrusted error, got exit code %d, stderr: %q", exitCode, stderr)
+	}
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "hook", "trust")
+	if exitCode != 0 {
+		t.Fatalf("gw hook trust exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	if want := filepath.Join(repo.Root, ".gw", "config") + "\n"; stdout != want {
+		t.Errorf("gw hook trust stdout = %q, want %q", stdout, want)
+	}
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "feature/inline-trusted"); exitCode != 0 {
+		t.Errorf("trusted command should run: exit code = %d, stderr = %q", exitCode, stderr)
+	}
+}
+
+// --- background hooks ---
+
+// waitHookStatus polls "gw hook status <target>" until its output contains want.
+func waitHookStatus(t *testing.T, dir, target, want string) string {
+	t.Helper()
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		stdout, stderr, exitCode := runGw(t, dir, "hook", "status", target)
+		if exitCode != 0 {
+			t.Fatalf("gw hook status exit code = %d; stderr: %s", exitCode, stderr)
+		}
+		if strings.Contains(stdout, want) {
+			return stdout
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("gw hook status never reported %q, last output: %q", want, stdout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestAsyncPostAdd(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	release := filepath.Join(t.TempDir(), "release")
+	repo.WriteConfig("[hooks.post-add]\nasync = true\n")
+	repo.WriteHook("post-add", "#!/bin/sh\necho installing\n"+
+		"i=0; while [ ! -e "+release+" ] && [ $i -lt 100 ]; do sleep 0.1; i=$((i+1)); done\necho installed\n")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "add", "feature/async")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	wtPath := strings.TrimSpace(stdout)
+	if !strings.Contains(stderr, "running in the background") {
+		t.Errorf("expected background notice, got stderr: %q", stderr)
+	}
+
+	// The job is recorded with its process by the time gw add returns.
+	status, _, _ := runGw(t, repo.Root, "hook", "status", "feature/async")
+	if !strings.HasPrefix(status, wtPath) || !strings.Contains(status, "post-add") || !strings.Contains(status, "running (pid ") {
+		t.Errorf("unexpected status: %q", status)
+	}
+
+	if err := os.WriteFile(release, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitHookStatus(t, repo.Root, "feature/async", "succeeded")
+
+	logs, stderr, exitCode := runGw(t, repo.Root, "hook", "logs", "feature/async")
+	if exitCode != 0 {
+		t.Fatalf("gw hook logs exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	if logs != "installing\ninstalled\n" {
+		t.Errorf("logs = %q", logs)
+	}
+
+	all, _, _ := runGw(t, repo.Root, "hook", "status")
+	if !strings.Contains(all, wtPath) {
+		t.Errorf("gw hook status without arguments should list %s, got: %q", wtPath, all)
+	}
+}
+
+func TestAsyncPostAdd_NoScripts(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("[hooks.post-add]\nasync = true\n")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feature/async-none")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if strings.Contains(stderr, "background") {
+		t.Errorf("expected no background notice without post-add scripts, got stderr: %q", stderr)
+	}
+	if _, _, exitCode := runGw(t, repo.Root, "hook", "status", "feature/async-none"); exitCode == 0 {
+		t.Error("no background hook should have been started")
+	}
+}
+
+func TestAsyncPostAdd_Failure(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("[hooks.post-add]\nasync = true\n")
+	repo.WriteHook("post-add", "#!/bin/sh\necho broken >&2\nexit 3\n")
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "feature/async-fail"); exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+
+	status := waitHookStatus(t, repo.Root, "feature/async-fail", "failed")
+	if !strings.Contains(status, "exit status 3") {
+		t.Errorf("expected exit status in status, got: %q", status)
+	}
+	logs, _, _ := runGw(t, repo.Root, "hook", "logs", "feature/async-fail")
+	if !strings.Contains(logs, "broken") || !strings.Contains(logs, "gw: warning: post-add hook failed") {
+		t.Errorf("logs = %q", logs)
+	}
+}
+
+func TestAsyncPostAdd_RemoveWaits(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	marker := filepath.Join(t.TempDir(), "done")
+	repo.WriteConfig("[hooks.post-add]\nasync = true\n")
+	repo.WriteHook("post-add", "#!/bin/sh\nsleep 1\ntouch "+marker+"\n")
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "feature/async-rm"); exitCode != 0 {
+		t.Fatalf("gw add exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "feature/async-rm")
+	if exitCode != 0 {
+		t.Fatalf("gw rm exit code = %d; stderr: %s", exitCode, stderr)
+	}
+
+	if !strings.Contains(stderr, "waiting for background post-add hook") {
+		t.Errorf("expected waiting notice, got stderr: %q", stderr)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("gw rm should wait for the background hook to finish")
+	}
+	if _, _, exitCode := runGw(t, repo.Root, "hook", "logs", "feature/async-rm"); exitCode == 0 {
+		t.Error("background hook state should be dropped with the worktree")
+	}
+}
+
+func TestAsyncPostAdd_ForceRemoveTerminates(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteConfig("[hooks.post-add]\nasync = true\n")
+	repo.WriteHook("post-add", "#!/bin/sh\nsleep 60\n")
+
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "feature/async-kill"); exitCode != 0 {
+		t.Fatalf("gw add exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	waitHookStatus(t, repo.Root, "feature/async-kill", "running")
+
+	start := time.Now()
+	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--force", "feature/async-kill")
+	if exitCode != 0 {
+		t.Fatalf("gw rm exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "terminating background post-add hook") {
+		t.Errorf("expected termination notice, got stderr: %q", stderr)
+	}
+	if elapsed := time.Since(start); elapsed > 15*time.Second {
+		t.Errorf("gw rm --force took %s; the hook should have been terminated", elapsed)
+	}
+}
+
+// --- gw hook run / list ---
+
+func TestHookRun(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/rerun")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	logFile := filepath.Join(t.TempDir(), "hook.log")
+	repo.WriteHook("post-add", "#!/bin/sh\necho \"cwd=$(pwd -P) path=$GW_WORKTREE_PATH branch=$GW_BRANCH created=$GW_BRANCH_CREATED\" >> "+logFile+"\n")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "hook", "run", "post-add", "feature/rerun")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("stdout should be empty, got %q", stdout)
+	}
+
+	// Without a target, the worktree containing the current directory is used
+	subDir := filepath.Join(wtPath, "sub")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, exitCode := runGw(t, subDir, "hook", "run", "post-add"); exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
 
-	if !strings.Contains(stderr, "gw: warning:") {
-		t.Errorf("expected warning in stderr, got: %q", stderr)
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
 	}
-
-	// Worktree should be gone
-	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
-		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	line := "cwd=" + wtPath + " path=" + wtPath + " branch=feature/rerun created=false\n"
+	if string(data) != line+line {
+		t.Errorf("hook log = %q, want %q twice", data, line)
 	}
 }
 
-func TestRm_PostRemoveHook_Failure(t *testing.T) {
+func TestHookRun_Failure(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("post-remove", "#!/bin/sh\necho \"cwd=$(pwd -P)\" >&2\nexit 1\n")
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/post-rm")
-	if exitCode != 0 {
-		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	_, stderr, exitCode := runGw(t, repo.Root, "hook", "run", "post-remove", repo.Root)
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
 	}
-	wtPath := strings.TrimSpace(addStdout)
+	if !strings.Contains(stderr, "cwd="+repo.Root) || !strings.Contains(stderr, "post-remove hook failed") {
+		t.Errorf("unexpected stderr: %q", stderr)
+	}
+}
 
-	// Set up failing post-remove hook
-	repo.WriteHook("post-remove", "#!/bin/sh\nexit 1\n")
+func TestHookRun_UnknownPhase(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
 
-	// Remove
-	_, stderr, exitCode := runGw(t, repo.Root, "rm", wtPath)
+	_, stderr, exitCode := runGw(t, repo.Root, "hook", "run", "post-commit")
 
-	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+	if exitCode != 1 || !strings.Contains(stderr, `unknown hook "post-commit"`) {
+		t.Errorf("exit code = %d, stderr = %q", exitCode, stderr)
 	}
+}
 
-	if !strings.Contains(stderr, "gw: warning:") {
-		t.Errorf("expected warning in stderr, got: %q", stderr)
+func TestHookList(t *testing.T) {
+	enableHookTrust(t)
+	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("pre-add", "#!/bin/sh\n")
+	repo.WriteHookNoExec("post-add.d/10-npm", "#!/bin/sh\n")
+	repo.WriteConfig("[hooks]\npost-remove = [\"echo done\"]\n")
+	if _, _, exitCode := runGw(t, repo.Root, "hook", "trust", filepath.Join(repo.Root, ".gw", "hooks", "pre-add")); exitCode != 0 {
+		t.Fatal("gw hook trust failed")
 	}
 
-	// Worktree should be gone
-	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
-		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	stdout, stderr, exitCode := runGw(t, repo.Root, "hook", "list")
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		got = append(got, strings.Join(strings.Fields(line), " "))
+	}
+	want := []string{
+		"pre-add pre-add ok",
+		"post-add post-add.d/10-npm not executable",
+		"pre-remove - not configured",
+		"post-remove .gw/config: echo done not trusted",
+		"pre-move - not configured",
+		"post-move - not configured",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
 	}
 }
 
-func TestRm_NotFound(t *testing.T) {
-	repo := testutil.NewTestRepo(t)
+// --- gw hook trust / untrust ---
 
-	// Create a subdirectory that is inside the repo but is not a worktree
-	subdir := filepath.Join(repo.Root, "subdir")
-	if err := os.MkdirAll(subdir, 0755); err != nil {
-		t.Fatal(err)
-	}
+// enableHookTrust turns the trust check back on for a test, with an empty trust store.
+func enableHookTrust(t *testing.T) {
+	t.Helper()
+	t.Setenv("GW_TRUST_ALL_HOOKS", "")
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+}
 
-	_, stderr, exitCode := runGw(t, repo.Root, "rm", subdir)
+func TestHook_UntrustedHookRefused(t *testing.T) {
+	enableHookTrust(t)
+	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("pre-add", "#!/bin/sh\nexit 0\n")
+
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feature/untrusted")
 
 	if exitCode != 1 {
 		t.Errorf("exit code = %d, want 1", exitCode)
 	}
-	if !strings.Contains(stderr, "not a git worktree") {
-		t.Errorf("expected 'not a git worktree' in stderr, got: %q", stderr)
+	if !strings.Contains(stderr, `hook "pre-add" is not trusted`) || !strings.Contains(stderr, "gw hook trust") {
+		t.Errorf("expected untrusted hook error, got: %q", stderr)
 	}
 }
 
-func TestRm_BranchSurvives(t *testing.T) {
+func TestHook_TrustAndModify(t *testing.T) {
+	enableHookTrust(t)
 	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("post-add", "#!/bin/sh\nexit 0\n")
+	repo.WriteHook("post-add.d/10-setup", "#!/bin/sh\nexit 0\n")
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/branch-survives")
+	stdout, stderr, exitCode := runGw(t, repo.Root, "hook", "trust")
 	if exitCode != 0 {
-		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+		t.Fatalf("gw hook trust exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	hooksDir := filepath.Join(repo.Root, ".gw", "hooks")
+	want := filepath.Join(hooksDir, "post-add") + "\n" + filepath.Join(hooksDir, "post-add.d", "10-setup") + "\n"
+	if stdout != want {
+		t.Errorf("gw hook trust stdout = %q, want %q", stdout, want)
 	}
-	wtPath := strings.TrimSpace(addStdout)
 
-	// Remove it
-	_, _, exitCode = runGw(t, repo.Root, "rm", wtPath)
+	_, stderr, exitCode = runGw(t, repo.Root, "add", "feature/trusted")
+	if exitCode != 0 || strings.Contains(stderr, "warning") {
+		t.Errorf("trusted hooks should run: exit code = %d, stderr = %q", exitCode, stderr)
+	}
+
+	// Modifying a trusted hook revokes its trust until it is trusted again
+	repo.WriteHook("post-add.d/10-setup", "#!/bin/sh\necho changed\n")
+	_, stderr, exitCode = runGw(t, repo.Root, "add", "feature/modified")
 	if exitCode != 0 {
-		t.Fatalf("gw rm exit code = %d, want 0", exitCode)
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+	if !strings.Contains(stderr, `hook "post-add.d/10-setup" has been modified since it was trusted`) {
+		t.Errorf("expected modified hook warning, got: %q", stderr)
 	}
 
-	// Branch should still exist
-	gitCmd := exec.Command("git", "rev-parse", "--verify", "feature/branch-survives")
-	gitCmd.Dir = repo.Root
-	if err := gitCmd.Run(); err != nil {
-		t.Error("branch should still exist after worktree removal")
+	if _, _, exitCode := runGw(t, repo.Root, "hook", "trust", filepath.Join(hooksDir, "post-add.d", "10-setup")); exitCode != 0 {
+		t.Fatalf("gw hook trust <path> exit code = %d", exitCode)
+	}
+	_, stderr, _ = runGw(t, repo.Root, "add", "feature/retrusted")
+	if strings.Contains(stderr, "warning") {
+		t.Errorf("re-trusted hook should run, got: %q", stderr)
 	}
 }
 
-func TestRm_EmptyStdout(t *testing.T) {
+func TestHook_Untrust(t *testing.T) {
+	enableHookTrust(t)
 	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("pre-add", "#!/bin/sh\nexit 0\n")
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/empty-stdout")
-	if exitCode != 0 {
-		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	if _, _, exitCode := runGw(t, repo.Root, "hook", "trust"); exitCode != 0 {
+		t.Fatalf("gw hook trust exit code = %d", exitCode)
 	}
-	wtPath := strings.TrimSpace(addStdout)
-
-	// Remove it
-	stdout, _, exitCode := runGw(t, repo.Root, "rm", wtPath)
-	if exitCode != 0 {
-		t.Fatalf("gw rm exit code = %d, want 0", exitCode)
+	if _, _, exitCode := runGw(t, repo.Root, "hook", "untrust"); exitCode != 0 {
+		t.Fatalf("gw hook untrust exit code = %d", exitCode)
 	}
 
-	if stdout != "" {
-		t.Errorf("expected empty stdout, got: %q", stdout)
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feature/untrusted-again")
+	if exitCode != 1 || !strings.Contains(stderr, "is not trusted") {
+		t.Errorf("untrusted hook should be refused: exit code = %d, stderr = %q", exitCode, stderr)
 	}
 }
 
-func TestRm_NoArgs(t *testing.T) {
+func TestHook_Trust_NoHooks(t *testing.T) {
+	enableHookTrust(t)
 	repo := testutil.NewTestRepo(t)
 
-	_, stderr, exitCode := runGw(t, repo.Root, "rm")
+	_, stderr, exitCode := runGw(t, repo.Root, "hook", "trust")
 
-	if exitCode != 1 {
-		t.Errorf("exit code = %d, want 1", exitCode)
-	}
-	if !strings.Contains(stderr, "path required") {
-		t.Errorf("expected 'path required' in stderr, got: %q", stderr)
+	if exitCode != 1 || !strings.Contains(stderr, "no hooks found") {
+		t.Errorf("exit code = %d, stderr = %q", exitCode, stderr)
 	}
 }
 
-func TestRm_MainWorktree(t *testing.T) {
+func TestHook_TrustAllHooks_GlobalConfig(t *testing.T) {
+	enableHookTrust(t)
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
 	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("pre-add", "#!/bin/sh\nexit 0\n")
 
-	_, stderr, exitCode := runGw(t, repo.Root, "rm", repo.Root)
+	// A repository cannot trust its own hooks
+	repo.WriteConfig("trust_all_hooks = true\n")
+	if _, _, exitCode := runGw(t, repo.Root, "add", "feature/repo-config"); exitCode != 1 {
+		t.Errorf("trust_all_hooks in .gw/config should be ignored, exit code = %d", exitCode)
+	}
 
-	if exitCode != 1 {
-		t.Errorf("exit code = %d, want 1", exitCode)
+	if err := os.MkdirAll(filepath.Join(xdg, "gw"), 0755); err != nil {
+		t.Fatal(err)
 	}
-	if !strings.Contains(stderr, "cannot remove the main worktree") {
-		t.Errorf("expected 'cannot remove the main worktree' in stderr, got: %q", stderr)
+	if err := os.WriteFile(filepath.Join(xdg, "gw", "config"), []byte("trust_all_hooks = true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "feature/global-config"); exitCode != 0 {
+		t.Errorf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
 }
 
-func TestRm_OutsideGitRepo(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	_, _, exitCode := runGw(t, tmpDir, "rm", "/nonexistent/path")
+func TestHook_TrustAllHooks_Env(t *testing.T) {
+	enableHookTrust(t)
+	t.Setenv("GW_TRUST_ALL_HOOKS", "true")
+	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("pre-add", "#!/bin/sh\nexit 0\n")
 
-	if exitCode != 1 {
-		t.Errorf("exit code = %d, want 1", exitCode)
+	if _, stderr, exitCode := runGw(t, repo.Root, "add", "feature/env"); exitCode != 0 {
+		t.Errorf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
 }
 
-func TestRm_ExtraArgs(t *testing.T) {
+func TestInit_TrustsTemplates(t *testing.T) {
+	enableHookTrust(t)
 	repo := testutil.NewTestRepo(t)
 
-	_, stderr, exitCode := runGw(t, repo.Root, "rm", "feature/foo", "extra")
-
-	if exitCode != 1 {
-		t.Errorf("exit code = %d, want 1", exitCode)
+	if _, _, exitCode := runGw(t, repo.Root, "init"); exitCode != 0 {
+		t.Fatalf("gw init exit code = %d", exitCode)
 	}
-	if !strings.Contains(stderr, "unexpected argument") {
-		t.Errorf("expected 'unexpected argument' in stderr, got: %q", stderr)
+
+	_, stderr, exitCode := runGw(t, repo.Root, "add", "feature/after-init")
+	if exitCode != 0 || strings.Contains(stderr, "not trusted") {
+		t.Errorf("hook templates from gw init should be trusted: exit code = %d, stderr = %q", exitCode, stderr)
 	}
 }
 
-func TestRm_ForceFlagBeforePath(t *testing.T) {
+// --- git worktree passthrough ---
+
+func TestPassthrough_LockUnlock(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("passthrough-lock", "passthrough-lock")
+
+	stdout, stderr, exitCode := runGw(t, repo.Root, "lock", "--reason", "on usb disk", wtPath)
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/flag-order")
 	if exitCode != 0 {
-		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("expected empty stdout, got: %q", stdout)
 	}
-	wtPath := strings.TrimSpace(addStdout)
 
-	// Remove with --force before path
-	_, _, exitCode = runGw(t, repo.Root, "rm", "--force", wtPath)
+	listOut, _, _ := runGw(t, repo.Root, "list", "--format", "{{.Path}} {{.Locked}} {{.LockedReason}}")
+	if !strings.Contains(listOut, wtPath+" true on usb disk") {
+		t.Errorf("expected worktree to be locked, got: %q", listOut)
+	}
 
-	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+	if _, stderr, exitCode := runGw(t, repo.Root, "unlock", wtPath); exitCode != 0 {
+		t.Fatalf("unlock exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
 
-	// Worktree directory should be gone
-	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
-		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	listOut, _, _ = runGw(t, repo.Root, "list", "--format", "{{.Path}} {{.Locked}}")
+	if !strings.Contains(listOut, wtPath+" false") {
+		t.Errorf("expected worktree to be unlocked, got: %q", listOut)
 	}
 }
 
-func TestRm_FromWorktree(t *testing.T) {
+func TestPassthrough_RelativePathFromWorktree(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
+	wtA := repo.CreateWorktree("passthrough-a", "passthrough-a")
+	wtB := repo.CreateWorktree("passthrough-b", "passthrough-b")
+
+	// Relative to the worktree the command is run from, not to the repo root
+	_, stderr, exitCode := runGw(t, wtA, "lock", "../"+filepath.Base(wtB))
 
-	// Create two worktrees
-	addStdout1, _, exitCode := runGw(t, repo.Root, "add", "feature/rm-source")
 	if exitCode != 0 {
-		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
-	sourcePath := strings.TrimSpace(addStdout1)
+	listOut, _, _ := runGw(t, repo.Root, "list", "--format", "{{.Path}} {{.Locked}}")
+	if !strings.Contains(listOut, wtB+" true") {
+		t.Errorf("expected %s to be locked, got: %q", wtB, listOut)
+	}
+}
 
-	addStdout2, _, exitCode := runGw(t, repo.Root, "add", "feature/rm-target")
-	if exitCode != 0 {
-		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+func TestPassthrough_Move(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("passthrough-move", "passthrough-move")
+	newPath := filepath.Join(filepath.Dir(repo.Root), "passthrough-moved")
+
+	subdir := filepath.Join(repo.Root, "sub")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatal(err)
 	}
-	targetPath := strings.TrimSpace(addStdout2)
 
-	// Remove the target from inside the source worktree
-	_, _, exitCode = runGw(t, sourcePath, "rm", targetPath)
+	// The destination is relative to the current directory, not to the repo root
+	_, stderr, exitCode := runGw(t, subdir, "move", wtPath, "../../passthrough-moved")
 
 	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
-
-	// Target worktree should be gone
-	if _, err := os.Stat(targetPath); !os.IsNotExist(err) {
-		t.Errorf("worktree directory should have been removed: %s", targetPath)
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("worktree should have been moved to %s: %v", newPath, err)
 	}
 }
 
-func TestRm_RelativePath(t *testing.T) {
+func TestPassthrough_Move_FollowsHookState(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
+	release := filepath.Join(t.TempDir(), "release")
+	repo.WriteConfig("[hooks.post-add]\nasync = true\n")
+	repo.WriteHook("post-add", "#!/bin/sh\n"+
+		"i=0; while [ ! -e "+release+" ] && [ $i -lt 100 ]; do sleep 0.1; i=$((i+1)); done\n"+
+		"echo port=3000 > \"$GW_OUTPUT_FILE\"\necho done\n")
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/rel-path")
+	stdout, stderr, exitCode := runGw(t, repo.Root, "add", "passthrough-state")
 	if exitCode != 0 {
-		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+		t.Fatalf("gw add exit code = %d; stderr: %s", exitCode, stderr)
 	}
-	wtPath := strings.TrimSpace(addStdout)
+	wtPath := strings.TrimSpace(stdout)
+	newPath := filepath.Join(filepath.Dir(wtPath), "passthrough-state-moved")
 
-	// Compute relative path from repo root to the worktree
-	relPath, err := filepath.Rel(repo.Root, wtPath)
-	if err != nil {
-		t.Fatal(err)
+	// Moving while the background hook runs would orphan its state.
+	_, stderr, exitCode = runGw(t, repo.Root, "move", wtPath, newPath)
+	if exitCode != 1 || !strings.Contains(stderr, "still running") {
+		t.Errorf("move during hook: exit code = %d, stderr = %q", exitCode, stderr)
 	}
 
-	// Remove using relative path
-	_, _, exitCode = runGw(t, repo.Root, "rm", relPath)
-
-	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+	if err := os.WriteFile(release, nil, 0644); err != nil {
+		t.Fatal(err)
 	}
+	waitHookStatus(t, repo.Root, "passthrough-state", "succeeded")
 
-	// Worktree directory should be gone
-	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
-		t.Errorf("worktree directory should have been removed: %s", wtPath)
+	if _, stderr, exitCode := runGw(t, repo.Root, "move", filepath.Base(wtPath), newPath); exitCode != 0 {
+		t.Fatalf("move: exit code = %d; stderr: %s", exitCode, stderr)
+	}
+	if got, want := listOutputs(t, repo.Root), map[string]map[string]string{newPath: {"port": "3000"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs after move = %v, want %v", got, want)
+	}
+	if logs, stderr, exitCode := runGw(t, repo.Root, "hook", "logs", newPath); exitCode != 0 || logs != "done\n" {
+		t.Errorf("hook logs after move: exit code = %d, stdout = %q, stderr = %q", exitCode, logs, stderr)
 	}
 }
 
-func TestRm_StaleWorktree(t *testing.T) {
+func TestPassthrough_GitFailure(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 
-	// Create a worktree
-	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/stale")
-	if exitCode != 0 {
-		t.Fatalf("gw add exit code = %d, want 0", exitCode)
-	}
-	wtPath := strings.TrimSpace(addStdout)
+	_, stderr, exitCode := runGw(t, repo.Root, "unlock", "/nonexistent/worktree")
 
-	// Manually delete the worktree directory to simulate a stale entry
-	if err := os.RemoveAll(wtPath); err != nil {
-		t.Fatal(err)
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
 	}
+	if !strings.Contains(stderr, "git worktree unlock failed") {
+		t.Errorf("expected failure message in stderr, got: %q", stderr)
+	}
+}
 
-	// Remove the stale worktree with --force from repo root
-	_, _, exitCode = runGw(t, repo.Root, "rm", "--force", wtPath)
+func TestPassthrough_OutsideGitRepo(t *testing.T) {
+	_, _, exitCode := runGw(t, t.TempDir(), "repair")
 
-	if exitCode != 0 {
-		t.Errorf("exit code = %d, want 0", exitCode)
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
 	}
 }
 
-func TestRm_ForceOnly_NoPath(t *testing.T) {
+func TestRemoveAlias_RunsHooks(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
+	marker := filepath.Join(t.TempDir(), "pre-remove-ran")
+	repo.WriteHook("pre-remove", "#!/bin/sh\ntouch "+marker+"\n")
 
-	_, stderr, exitCode := runGw(t, repo.Root, "rm", "--force")
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/remove-alias")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
 
-	if exitCode != 1 {
-		t.Errorf("exit code = %d, want 1", exitCode)
+	_, stderr, exitCode := runGw(t, repo.Root, "remove", wtPath)
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0; stderr: %s", exitCode, stderr)
 	}
-	if !strings.Contains(stderr, "path required") {
-		t.Errorf("expected 'path required' in stderr, got: %q", stderr)
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("pre-remove hook should run for gw remove")
 	}
 }
 
@@ -1105,6 +4263,68 @@ func TestCompletion_Rm_AfterForceFlag(t *testing.T) {
 	}
 }
 
+func TestCompletion_Rm_Branches(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	if _, _, exitCode := runGw(t, repo.Root, "add", "feature/rm-branch-complete"); exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+
+	stdout, _, exitCode := runGw(t, repo.Root, "rm", "--generate-shell-completion")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if !strings.Contains(stdout, "feature/rm-branch-complete\n") {
+		t.Errorf("expected branch name in completion output, got: %q", stdout)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if line == "main" {
+			t.Errorf("expected main worktree branch NOT in completion output, got: %q", stdout)
+		}
+	}
+}
+
+func TestCompletion_Rm_AfterBranchFlag(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	addStdout, _, exitCode := runGw(t, repo.Root, "add", "feature/branch-flag-comp")
+	if exitCode != 0 {
+		t.Fatalf("gw add exit code = %d, want 0", exitCode)
+	}
+	wtPath := strings.TrimSpace(addStdout)
+
+	stdout, _, exitCode := runGw(t, repo.Root, "rm", "--branch", "--generate-shell-completion")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if !strings.Contains(stdout, "feature/branch-flag-comp") {
+		t.Errorf("expected branch name in completion output after --branch, got: %q", stdout)
+	}
+	if strings.Contains(stdout, wtPath) {
+		t.Errorf("expected no worktree paths after --branch, got: %q", stdout)
+	}
+}
+
+func TestCompletion_Path_WorktreeBranches(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateWorktree("path-comp-wt", "path-comp")
+	repo.CreateBranch("not-checked-out")
+
+	stdout, _, exitCode := runGw(t, repo.Root, "path", "--generate-shell-completion")
+
+	if exitCode != 0 {
+		t.Fatalf("exit code = %d, want 0", exitCode)
+	}
+	if !strings.Contains(stdout, "path-comp\n") || !strings.Contains(stdout, "main\n") {
+		t.Errorf("expected worktree branches in completion output, got: %q", stdout)
+	}
+	if strings.Contains(stdout, "not-checked-out") {
+		t.Errorf("expected only checked-out branches, got: %q", stdout)
+	}
+}
+
 func TestCompletion_CompletionSubcommand(t *testing.T) {
 	stdout, _, exitCode := runGw(t, t.TempDir(), "completion", "bash")
 
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -13,7 +14,7 @@ import (
 )
 
 // Add implements the "gw add" command.
-func Add(branch, from string) error {
+func Add(ctx context.Context, branch, from string) error {
 	// 1. Detect repo root
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -25,26 +26,24 @@ func Add(branch, from string) error {
 		return err
 	}
 
-	// 2. Calculate worktree path
 	cfg, err := config.Load(repoRoot)
 	if err != nil {
 		return err
 	}
+	cfg = cfg.ForBranch(branch)
 
-	repoName := git.RepoName(repoRoot)
-	baseDir := pathutil.BaseDir(repoRoot, repoName, cfg.WorktreesDir)
-
-	baseDir, err = filepath.Abs(baseDir)
+	// 2. Calculate worktree path
+	wtPath, err := computeWorktreePath(repoRoot, branch)
 	if err != nil {
 		return err
 	}
 
-	wtPath, err := pathutil.ComputePath(baseDir, branch)
+	worktrees, err := git.ListWorktrees(repoRoot)
 	if err != nil {
 		return err
 	}
-
-	if err := pathutil.ValidatePath(wtPath); err != nil {
+	wtPath, err = resolvePathCollision(wtPath, cfg.OnCollision, worktreeOwners(worktrees))
+	if err != nil {
 		return err
 	}
 
@@ -59,39 +58,47 @@ func Add(branch, from string) error {
 	}
 
 	var gitArgs []string
+	var baseRef string
 	if !exists {
-		gitArgs = []string{"worktree", "add", wtPath, "-b", branch}
-		if from != "" {
-			gitArgs = append(gitArgs, from)
-		} else {
-			defaultBranch, err := git.DefaultBranch(repoRoot)
-			if err != nil {
-				return err
-			}
-
-			remoteRef := "origin/" + defaultBranch
-			remoteExists, err := git.RemoteRefExists(repoRoot, remoteRef)
+		baseRef = from
+		if baseRef == "" {
+			baseRef = cfg.StartRef()
+		}
+		if baseRef == "" {
+			baseRef, err = git.DefaultRef(repoRoot)
 			if err != nil {
 				return err
 			}
-
-			if remoteExists {
-				gitArgs = append(gitArgs, remoteRef)
-			} else {
-				gitArgs = append(gitArgs, defaultBranch)
-			}
 		}
+		gitArgs = []string{"worktree", "add", wtPath, "-b", branch, baseRef}
 	} else {
 		gitArgs = []string{"worktree", "add", wtPath, branch}
 	}
 
-	// Ensure base directory exists
-	if err := pathutil.EnsureBaseDir(baseDir); err != nil {
+	startPoint := baseRef
+	if exists {
+		startPoint = branch
+	}
+	headSHA, err := git.ResolveCommit(repoRoot, startPoint)
+	if err != nil {
+		return err
+	}
+	in := hookInput{
+		WorktreePath: wtPath,
+		Branch:       branch,
+		HeadSHA:      headSHA,
+		BaseRef:      baseRef,
+		Flags:        map[string]bool{"branch_created": !exists},
+	}
+
+	// Ensure the parent directory exists (the base directory, or deeper with a nested path_template)
+	if err := pathutil.EnsureBaseDir(filepath.Dir(wtPath)); err != nil {
 		return err
 	}
 
 	// 4. Run pre-add hook (at repo root)
-	if err := hook.Run(repoRoot, "pre-add", repoRoot, wtPath, branch, os.Stderr); err != nil {
+	if err := runHook(ctx, repoRoot, "pre-add", in.at(repoRoot)); err != nil {
+		dropOutputs(repoRoot, wtPath)
 		return fmt.Errorf("pre-add hook failed: %w", err)
 	}
 
@@ -102,12 +109,30 @@ func Add(branch, from string) error {
 	gitCmd.Stderr = os.Stderr
 
 	if err := gitCmd.Run(); err != nil {
+		dropOutputs(repoRoot, wtPath)
 		return fmt.Errorf("git worktree add failed: %w", err)
 	}
 
-	// 6. Run post-add hook (in worktree directory)
-	if err := hook.Run(repoRoot, "post-add", wtPath, wtPath, branch, os.Stderr); err != nil {
-		fmt.Fprintf(os.Stderr, "gw: warning: post-add hook failed: %v\n", err)
+	// 6. Run post-add hook (in worktree directory), detached if configured as async
+	async := cfg.HookAsync("post-add") && cfg.HookEnabled("post-add")
+	if async {
+		// Without scripts there is nothing to run in the background. If they cannot be
+		// listed, the hook runs in the foreground, which reports the error.
+		async, _ = hook.HasScripts(hook.Params{
+			RepoRoot:  repoRoot,
+			Name:      "post-add",
+			GlobalDir: globalHooksDir(),
+			Commands:  hookCommands(cfg, "post-add"),
+		})
+	}
+	if async {
+		if err := startBackgroundHook(repoRoot, "post-add", in.at(wtPath)); err != nil {
+			fmt.Fprintf(os.Stderr, "gw: warning: failed to start post-add hook in the background: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "gw: post-add hook is running in the background; see \"gw hook logs %s\"\n", branch)
+		}
+	} else if err := runHook(ctx, repoRoot, "post-add", in.at(wtPath)); err != nil {
+		warnHookFailed("post-add", err)
 	}
 
 	// 7. Output path to stdout
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin0606/gw/internal/config"
+	"github.com/gin0606/gw/internal/git"
+	"github.com/gin0606/gw/internal/pathutil"
+)
+
+// ConfigExplain implements the "gw config explain" command.
+// It prints the rule that matches branch and the settings in effect for it,
+// each with the file or rule it comes from.
+func ConfigExplain(branch string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	base, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+	cfg := base.ForBranch(branch)
+	rule := cfg.Rule()
+
+	wtPath, err := computeWorktreePath(repoRoot, branch)
+	if err != nil {
+		return err
+	}
+
+	// source describes where the value of key comes from; overridden says whether the rule sets it.
+	source := func(key string, overridden bool) string {
+		if overridden {
+			return rule.Name()
+		}
+		if s := cfg.Source(key); s != "" {
+			return s
+		}
+		return "default"
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "branch\t%s\n", branch)
+	if rule != nil {
+		fmt.Fprintf(tw, "rule\t%s\t%s\n", rule.Pattern(), rule.Name())
+	} else {
+		fmt.Fprintf(tw, "rule\t-\tno rule matches\n")
+	}
+
+	worktreesDir := pathutil.BaseDir(repoRoot, git.RepoName(repoRoot), cfg.WorktreesDir)
+	fmt.Fprintf(tw, "worktrees_dir\t%s\t%s\n", worktreesDir, source("worktrees_dir", rule != nil && rule.WorktreesDir != ""))
+
+	tmpl := cfg.PathTemplate
+	if tmpl == "" {
+		tmpl = pathutil.DefaultPathTemplate
+	}
+	fmt.Fprintf(tw, "path_template\t%s\t%s\n", tmpl, source("path_template", rule != nil && rule.PathTemplate != ""))
+
+	strategy := cfg.Sanitize.Strategy
+	if strategy == "" {
+		strategy = pathutil.StrategyFlatten
+	}
+	fmt.Fprintf(tw, "sanitize.strategy\t%s\t%s\n", strategy, source("sanitize.strategy", false))
+
+	if ref := cfg.StartRef(); ref != "" {
+		fmt.Fprintf(tw, "from\t%s\t%s\n", ref, rule.Name())
+	} else if ref, err := git.DefaultRef(repoRoot); err == nil {
+		fmt.Fprintf(tw, "from\t%s\tdefault\n", ref)
+	} else {
+		fmt.Fprintf(tw, "from\t-\t%v\n", err)
+	}
+
+	for _, phase := range HookPhases {
+		state := []string{"enabled"}
+		src := "default"
+		if !cfg.HookEnabled(phase) {
+			state[0] = "disabled"
+			src = rule.Name()
+		}
+		if cfg.HookAsync(phase) {
+			state = append(state, "async")
+			if src == "default" {
+				src = cfg.Source("hooks." + phase + ".async")
+			}
+		}
+		if t := cfg.HookTimeout(phase); t > 0 {
+			state = append(state, "timeout "+t.String())
+			if src == "default" {
+				src = cfg.Source("hooks." + phase + ".timeout")
+			}
+		}
+		fmt.Fprintf(tw, "hooks.%s\t%s\t%s\n", phase, strings.Join(state, ", "), src)
+	}
+	fmt.Fprintf(tw, "path\t%s\n", wtPath)
+	return tw.Flush()
+}
+
+// ConfigGet implements the "gw config get" command.
+// It prints the value in effect for key, one line per command for hooks.<name>.commands.
+func ConfigGet(key string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	values, err := cfg.Get(key)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("%s is not set", key)
+	}
+	for _, v := range values {
+		fmt.Println(v)
+	}
+	return nil
+}
+
+// ConfigSet implements the "gw config set" command.
+// It writes key to .gw/config, or to the global config file if global is true.
+func ConfigSet(key, value string, global bool) error {
+	path, err := configPath(global)
+	if err != nil {
+		return err
+	}
+	if err := validateHookKey(key); err != nil {
+		return err
+	}
+	return config.SetKey(path, key, value, global)
+}
+
+// ConfigUnset implements the "gw config unset" command.
+// It removes key from .gw/config, or from the global config file if global is true.
+func ConfigUnset(key string, global bool) error {
+	path, err := configPath(global)
+	if err != nil {
+		return err
+	}
+	return config.UnsetKey(path, key, global)
+}
+
+// ConfigList implements the "gw config list" command.
+// It prints every key set in the config files with its value and the file that sets it.
+func ConfigList() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range cfg.Settings() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, s.Value, s.Source)
+	}
+	return tw.Flush()
+}
+
+// ConfigCheck implements the "gw config check" command.
+// It prints the problems found in the config files and fails if there are any.
+func ConfigCheck() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	problems, err := config.Check(repoRoot, HookPhases)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	switch len(problems) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("found 1 problem")
+	default:
+		return fmt.Errorf("found %d problems", len(problems))
+	}
+}
+
+func loadConfig() (*config.Config, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return nil, err
+	}
+	return config.Load(repoRoot)
+}
+
+// configPath returns the config file "gw config set" and "gw config unset" edit.
+func configPath(global bool) (string, error) {
+	if global {
+		dir := config.GlobalDir()
+		if dir == "" {
+			return "", fmt.Errorf("cannot locate the global config directory: set XDG_CONFIG_HOME or HOME")
+		}
+		return filepath.Join(dir, "config"), nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(repoRoot, ".gw", "config"), nil
+}
+
+// validateHookKey rejects hooks.<name>.* keys for hooks gw does not run.
+func validateHookKey(key string) error {
+	parts := strings.Split(key, ".")
+	if len(parts) == 3 && parts[0] == "hooks" && !slices.Contains(HookPhases, parts[1]) {
+		return fmt.Errorf("unknown hook %q (expected one of %s)", parts[1], strings.Join(HookPhases, ", "))
+	}
+	return nil
+}
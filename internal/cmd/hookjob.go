@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gin0606/gw/internal/git"
+)
+
+// hookJob is a hook running in the background for a worktree.
+// Each worktree has at most one job, stored in <git-common-dir>/gw/jobs/ as three files:
+// <id>.json (this struct, written by gw add), <id>.log (the hook's output), and
+// <id>.result (a hookJobResult, written by the background process when the hook finishes).
+type hookJob struct {
+	Hook      string    `json:"hook"`
+	RepoRoot  string    `json:"repo_root"`
+	Worktree  string    `json:"worktree"`
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	Input     hookInput `json:"input"`
+}
+
+// hookJobResult is the outcome of a background hook.
+type hookJobResult struct {
+	Error      string    `json:"error,omitempty"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// jobPollInterval is how often the state of a background hook is checked while waiting for it.
+const jobPollInterval = 200 * time.Millisecond
+
+// hookJobPath returns the path of the job file of the background hook of a worktree.
+// The log and result files share its name with a different extension.
+func hookJobPath(repoRoot, worktreePath string) (string, error) {
+	commonDir, err := git.CommonDir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(worktreePath))
+	return filepath.Join(commonDir, "gw", "jobs", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func jobLogPath(jobPath string) string {
+	return strings.TrimSuffix(jobPath, ".json") + ".log"
+}
+
+func jobResultPath(jobPath string) string {
+	return strings.TrimSuffix(jobPath, ".json") + ".result"
+}
+
+// readJSONFile decodes the JSON file at path into v. It reports false if the file does not exist.
+func readJSONFile(path string, v any) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// loadHookJob returns the background hook of a worktree, or nil if none has run.
+func loadHookJob(repoRoot, worktreePath string) (*hookJob, error) {
+	path, err := hookJobPath(repoRoot, worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	var job hookJob
+	ok, err := readJSONFile(path, &job)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// listHookJobs returns the background hooks of all worktrees, sorted by worktree path.
+func listHookJobs(repoRoot string) ([]*hookJob, error) {
+	path, err := hookJobPath(repoRoot, "")
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*hookJob
+	for _, f := range files {
+		var job hookJob
+		if _, err := readJSONFile(f, &job); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+	slices.SortFunc(jobs, func(a, b *hookJob) int { return strings.Compare(a.Worktree, b.Worktree) })
+	return jobs, nil
+}
+
+// result returns the outcome of the job, or nil if the background process has not reported one.
+func (j *hookJob) result() (*hookJobResult, error) {
+	path, err := hookJobPath(j.RepoRoot, j.Worktree)
+	if err != nil {
+		return nil, err
+	}
+	var res hookJobResult
+	ok, err := readJSONFile(jobResultPath(path), &res)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// running reports whether the background process of the job is still alive without having reported a result.
+func (j *hookJob) running() bool {
+	if res, err := j.result(); res != nil || err != nil || j.PID == 0 {
+		return false
+	}
+	err := syscall.Kill(j.PID, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// describe summarizes the state of the job for "gw hook status".
+func (j *hookJob) describe() string {
+	res, err := j.result()
+	switch {
+	case err != nil:
+		return "unknown: " + err.Error()
+	case res == nil && j.running():
+		return fmt.Sprintf("running (pid %d, %s)", j.PID, time.Since(j.StartedAt).Round(time.Second))
+	case res == nil:
+		return "failed: exited without reporting a result"
+	case res.Error != "":
+		return fmt.Sprintf("failed (%s): %s", res.FinishedAt.Sub(j.StartedAt).Round(time.Second), res.Error)
+	default:
+		return fmt.Sprintf("succeeded (%s)", res.FinishedAt.Sub(j.StartedAt).Round(time.Second))
+	}
+}
+
+// startBackgroundHook runs a hook in a detached gw process ("gw hook run-background"),
+// so that the command does not wait for it. The hook's output goes to the job's log file.
+// A previous job of the same worktree is replaced.
+func startBackgroundHook(repoRoot, name string, in hookInput) error {
+	jobPath, err := hookJobPath(repoRoot, in.WorktreePath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(jobPath), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(jobResultPath(jobPath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	logFile, err := os.Create(jobLogPath(jobPath))
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	// The background process waits for its stdin to be closed, so that it does not read
+	// the job file before it has been written with its PID.
+	stdin, ready, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer ready.Close()
+	c := exec.Command(exe, "hook", "run-background", jobPath)
+	c.Dir = in.Dir
+	c.Stdin = stdin
+	c.Stdout = logFile
+	c.Stderr = logFile
+	// A new session keeps the hook alive when the terminal goes away or Ctrl-C is pressed.
+	c.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	err = c.Start()
+	stdin.Close()
+	if err != nil {
+		return err
+	}
+
+	job := hookJob{
+		Hook:      name,
+		RepoRoot:  repoRoot,
+		Worktree:  in.WorktreePath,
+		PID:       c.Process.Pid,
+		StartedAt: time.Now(),
+		Input:     in,
+	}
+	if err := writeJSONFile(jobPath, job); err != nil {
+		c.Process.Kill()
+		return err
+	}
+	return c.Process.Release()
+}
+
+// RunBackgroundHook implements the hidden "gw hook run-background" command:
+// it runs the hook of the job file written by startBackgroundHook and records its result.
+func RunBackgroundHook(ctx context.Context, jobPath string) error {
+	if _, err := io.Copy(io.Discard, os.Stdin); err != nil {
+		return err
+	}
+	var job hookJob
+	ok, err := readJSONFile(jobPath, &job)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("job file %s not found", jobPath)
+	}
+
+	res := hookJobResult{}
+	if err := runHook(ctx, job.RepoRoot, job.Hook, job.Input); err != nil {
+		warnHookFailed(job.Hook, err)
+		res.Error = err.Error()
+	}
+	res.FinishedAt = time.Now()
+	return writeJSONFile(jobResultPath(jobPath), res)
+}
+
+// waitBackgroundHook blocks until the background hook of a worktree, if one is running, has exited.
+// With kill, the hook is terminated first instead of being allowed to finish.
+func waitBackgroundHook(ctx context.Context, repoRoot, worktreePath string, kill bool) error {
+	job, err := loadHookJob(repoRoot, worktreePath)
+	if err != nil || job == nil || !job.running() {
+		return err
+	}
+
+	if kill {
+		fmt.Fprintf(os.Stderr, "gw: terminating background %s hook of %s (pid %d)\n", job.Hook, worktreePath, job.PID)
+		if err := syscall.Kill(job.PID, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("failed to terminate background %s hook: %w", job.Hook, err)
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "gw: waiting for background %s hook of %s to finish (pid %d)\n", job.Hook, worktreePath, job.PID)
+	}
+
+	for job.running() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jobPollInterval):
+		}
+	}
+	return nil
+}
+
+// ensureNoRunningHook returns an error if the background hook of a worktree is still running,
+// for commands that cannot proceed while it uses the worktree.
+func ensureNoRunningHook(repoRoot, worktreePath string) error {
+	job, err := loadHookJob(repoRoot, worktreePath)
+	if err != nil {
+		return err
+	}
+	if job != nil && job.running() {
+		return fmt.Errorf("background %s hook of %s is still running; wait for it to finish (see \"gw hook status\")", job.Hook, worktreePath)
+	}
+	return nil
+}
+
+// dropHookJob deletes the background hook state and log of a worktree.
+func dropHookJob(repoRoot, worktreePath string) {
+	jobPath, err := hookJobPath(repoRoot, worktreePath)
+	if err == nil {
+		for _, p := range []string{jobPath, jobLogPath(jobPath), jobResultPath(jobPath)} {
+			if rmErr := os.Remove(p); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
+				err = rmErr
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gw: warning: failed to delete background hook state: %v\n", err)
+	}
+}
+
+// moveHookJob carries the background hook state and log of a worktree over to its new path.
+// The hook must not be running.
+func moveHookJob(repoRoot, oldPath, newPath string) {
+	job, err := loadHookJob(repoRoot, oldPath)
+	if err == nil && job != nil {
+		err = func() error {
+			oldJob, err := hookJobPath(repoRoot, oldPath)
+			if err != nil {
+				return err
+			}
+			newJob, err := hookJobPath(repoRoot, newPath)
+			if err != nil {
+				return err
+			}
+			for _, rename := range [][2]string{
+				{jobLogPath(oldJob), jobLogPath(newJob)},
+				{jobResultPath(oldJob), jobResultPath(newJob)},
+			} {
+				if err := os.Rename(rename[0], rename[1]); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+			}
+			job.Worktree = newPath
+			if err := writeJSONFile(newJob, job); err != nil {
+				return err
+			}
+			return os.Remove(oldJob)
+		}()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gw: warning: failed to move background hook state: %v\n", err)
+	}
+}
+
+// HookStatus implements the "gw hook status" command.
+// It prints the state of the background hook of each worktree, or only of target if given.
+func HookStatus(target string) error {
+	repoRoot, jobs, err := resolveHookJobs(target)
+	if err != nil {
+		return err
+	}
+	if jobs == nil {
+		if jobs, err = listHookJobs(repoRoot); err != nil {
+			return err
+		}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, j := range jobs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", j.Worktree, j.Hook, j.describe())
+	}
+	return tw.Flush()
+}
+
+// HookLogs implements the "gw hook logs" command.
+// It prints the log of the background hook of target; with follow, it keeps printing
+// new output until the hook exits.
+func HookLogs(ctx context.Context, target string, follow bool) error {
+	repoRoot, jobs, err := resolveHookJobs(target)
+	if err != nil {
+		return err
+	}
+	job := jobs[0]
+	jobPath, err := hookJobPath(repoRoot, job.Worktree)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(jobLogPath(jobPath))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for {
+		// Check before copying so that output written just before the hook exited is not missed.
+		running := follow && job.running()
+		if _, err := io.Copy(os.Stdout, f); err != nil {
+			return err
+		}
+		if !running {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+// resolveHookJobs returns the repository root of the current directory and, if target is
+// not empty, the background hook of the worktree it designates (as a path or branch).
+// jobs is nil when target is empty.
+func resolveHookJobs(target string) (repoRoot string, jobs []*hookJob, err error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	repoRoot, err = git.RepoRoot(cwd)
+	if err != nil {
+		return "", nil, err
+	}
+	if target == "" {
+		return repoRoot, nil, nil
+	}
+
+	worktrees, err := git.ListWorktrees(repoRoot)
+	if err != nil {
+		return "", nil, err
+	}
+	wt, err := resolveWorktree(worktrees, target, false)
+	if err != nil {
+		return "", nil, err
+	}
+	job, err := loadHookJob(repoRoot, wt.Path)
+	if err != nil {
+		return "", nil, err
+	}
+	if job == nil {
+		return "", nil, fmt.Errorf("no background hook has run for %s", wt.Path)
+	}
+	return repoRoot, []*hookJob{job}, nil
+}
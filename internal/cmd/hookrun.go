@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin0606/gw/internal/config"
+	"github.com/gin0606/gw/internal/git"
+	"github.com/gin0606/gw/internal/hook"
+)
+
+// HookPhases lists the hook phases gw runs, in lifecycle order.
+var HookPhases = []string{"pre-add", "post-add", "pre-remove", "post-remove", "pre-move", "post-move"}
+
+// HookRun implements the "gw hook run" command.
+// It runs a hook phase for an existing worktree (target, or the worktree containing the
+// current directory) with the working directory and environment of the real operation.
+// Flags such as GW_FORCE are false, and pre-move/post-move see the same old and new values.
+func HookRun(ctx context.Context, phase, target string) error {
+	if !slices.Contains(HookPhases, phase) {
+		return fmt.Errorf("unknown hook %q (expected one of %s)", phase, strings.Join(HookPhases, ", "))
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	worktrees, err := git.ListWorktrees(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	var wt git.Worktree
+	if target == "" {
+		wt, err = worktreeContaining(worktrees, cwd)
+	} else {
+		wt, err = resolveWorktree(worktrees, target, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	in := hookInput{
+		WorktreePath: wt.Path,
+		Branch:       wt.Branch,
+		HeadSHA:      wt.Head,
+		Manual:       true,
+	}
+	switch phase {
+	case "pre-add", "post-add":
+		in.Flags = map[string]bool{"branch_created": false}
+	case "pre-remove", "post-remove":
+		in.Flags = map[string]bool{"force": false}
+	case "pre-move", "post-move":
+		in.Move = &hook.EventMove{
+			OldBranch:       wt.Branch,
+			NewBranch:       wt.Branch,
+			OldWorktreePath: wt.Path,
+			NewWorktreePath: wt.Path,
+		}
+	}
+
+	// Same working directories as gw add and gw rm: the repository root while the worktree does not exist.
+	dir := wt.Path
+	if phase == "pre-add" || phase == "post-remove" {
+		dir = repoRoot
+	}
+
+	if err := runHook(ctx, repoRoot, phase, in.at(dir)); err != nil {
+		return fmt.Errorf("%s hook failed: %w", phase, err)
+	}
+	return nil
+}
+
+// HookList implements the "gw hook list" command.
+// It prints the scripts and inline commands of each hook phase in execution order,
+// with "ok" or the reason gw would refuse to run them.
+func HookList() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, phase := range HookPhases {
+		scripts, err := hook.Scripts(hook.Params{
+			RepoRoot:  repoRoot,
+			Name:      phase,
+			GlobalDir: globalHooksDir(),
+			Commands:  hookCommands(cfg, phase),
+			Trust:     trustStore(cfg),
+		})
+		if err != nil {
+			return err
+		}
+		if len(scripts) == 0 {
+			fmt.Fprintf(tw, "%s\t-\tnot configured\n", phase)
+			continue
+		}
+		for _, s := range scripts {
+			state := "ok"
+			if s.Problem != "" {
+				state = s.Problem
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", phase, s.Name, state)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin0606/gw/internal/config"
+	"github.com/gin0606/gw/internal/git"
+	"github.com/gin0606/gw/internal/hook"
+)
+
+// Version is the gw version exposed to hooks as GW_VERSION. It is set by main.
+var Version = "dev"
+
+// hookInput describes the operation a hook runs for.
+// It is exposed to hooks both as GW_* environment variables and as the JSON event on stdin.
+// Background hooks keep it in their job file.
+type hookInput struct {
+	Dir          string          `json:"dir"` // working directory of the hook
+	WorktreePath string          `json:"worktree_path"`
+	Branch       string          `json:"branch"`
+	HeadSHA      string          `json:"head_sha"`
+	BaseRef      string          `json:"base_ref,omitempty"`
+	Flags        map[string]bool `json:"flags"` // exported as GW_<NAME>=true|false, e.g. "force" -> GW_FORCE
+	Move         *hook.EventMove `json:"move,omitempty"`
+	Manual       bool            `json:"-"` // run by "gw hook run"; hooks disabled by a rule still run
+}
+
+// at returns a copy of in that runs in dir.
+func (in hookInput) at(dir string) hookInput {
+	in.Dir = dir
+	return in
+}
+
+// runHook runs the repository and global scripts of a hook with the configured settings,
+// sending their output to stderr.
+func runHook(ctx context.Context, repoRoot, name string, in hookInput) error {
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+	if !in.Manual && !cfg.ForBranch(in.Branch).HookEnabled(name) {
+		return nil
+	}
+
+	repoName := git.RepoName(repoRoot)
+	// Sanitize fails for detached worktrees (no branch); the name is empty then.
+	sanitized, _ := cfg.Sanitizer().Sanitize(in.Branch)
+
+	env := []string{
+		"GW_REPO_NAME=" + repoName,
+		"GW_SANITIZED_NAME=" + sanitized,
+		"GW_HEAD_SHA=" + in.HeadSHA,
+		"GW_BASE_REF=" + in.BaseRef,
+		"GW_VERSION=" + Version,
+	}
+	for _, flag := range slices.Sorted(maps.Keys(in.Flags)) {
+		env = append(env, "GW_"+strings.ToUpper(flag)+"="+strconv.FormatBool(in.Flags[flag]))
+	}
+	stored, err := loadOutputs(repoRoot)
+	if err != nil {
+		return err
+	}
+	outputs := stored[in.WorktreePath]
+	for _, key := range slices.Sorted(maps.Keys(outputs)) {
+		env = append(env, "GW_OUTPUT_"+strings.ToUpper(key)+"="+outputs[key])
+	}
+
+	outputFile, err := os.CreateTemp("", "gw-output-*")
+	if err != nil {
+		return err
+	}
+	outputFile.Close()
+	defer os.Remove(outputFile.Name())
+	env = append(env, "GW_OUTPUT_FILE="+outputFile.Name())
+
+	if m := in.Move; m != nil {
+		env = append(env,
+			"GW_OLD_BRANCH="+m.OldBranch,
+			"GW_NEW_BRANCH="+m.NewBranch,
+			"GW_OLD_WORKTREE_PATH="+m.OldWorktreePath,
+			"GW_NEW_WORKTREE_PATH="+m.NewWorktreePath,
+		)
+	}
+
+	runErr := hook.Run(ctx, hook.Params{
+		RepoRoot:     repoRoot,
+		Name:         name,
+		Dir:          in.Dir,
+		WorktreePath: in.WorktreePath,
+		Branch:       in.Branch,
+		GlobalDir:    globalHooksDir(),
+		Commands:     hookCommands(cfg, name),
+		Trust:        trustStore(cfg),
+		Env:          env,
+		Event: &hook.Event{
+			GwVersion: Version,
+			Repo:      hook.EventRepo{Name: repoName},
+			Worktree:  hook.EventWorktree{SanitizedName: sanitized, HeadSHA: in.HeadSHA},
+			BaseRef:   in.BaseRef,
+			Flags:     in.Flags,
+			Move:      in.Move,
+			Outputs:   outputs,
+		},
+		Timeout: cfg.HookTimeout(name),
+		Output:  os.Stderr,
+	})
+
+	// Outputs are kept even if the hook failed, e.g. a port allocated before a later step broke.
+	if err := recordOutputs(repoRoot, in.WorktreePath, outputFile.Name()); err != nil {
+		fmt.Fprintf(os.Stderr, "gw: warning: outputs of the %s hook were not saved: %v\n", name, err)
+	}
+
+	return runErr
+}
+
+// hookCommands converts the inline commands of a hook in cfg for hook.Run.
+func hookCommands(cfg *config.Config, name string) []hook.Command {
+	var commands []hook.Command
+	for _, c := range cfg.HookCommands(name) {
+		commands = append(commands, hook.Command{Run: c.Run, Source: c.Source, Global: c.Global})
+	}
+	return commands
+}
+
+// recordOutputs merges the outputs a hook wrote to outputFile into the stored outputs of the worktree.
+func recordOutputs(repoRoot, worktreePath, outputFile string) error {
+	outputs, err := hook.ReadOutputs(outputFile)
+	if err != nil {
+		return err
+	}
+	if len(outputs) == 0 {
+		return nil
+	}
+	return updateOutputs(repoRoot, func(o worktreeOutputs) {
+		if o[worktreePath] == nil {
+			o[worktreePath] = map[string]string{}
+		}
+		maps.Copy(o[worktreePath], outputs)
+	})
+}
+
+// warnHookFailed prints a warning for each failed script of a hook whose failure does not abort the command.
+func warnHookFailed(name string, err error) {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, e := range errs {
+		fmt.Fprintf(os.Stderr, "gw: warning: %s hook failed: %v\n", name, e)
+	}
+}
+
+// trustAllHooksEnv disables the hook trust check when set to a true value (e.g. in CI).
+const trustAllHooksEnv = "GW_TRUST_ALL_HOOKS"
+
+// hookTrustStore returns the store of trusted repository hook scripts.
+func hookTrustStore() (hook.TrustStore, error) {
+	dir := config.DataDir()
+	if dir == "" {
+		return hook.TrustStore{}, fmt.Errorf("cannot determine the data directory for the hook trust store; set XDG_DATA_HOME")
+	}
+	return hook.TrustStore{Path: filepath.Join(dir, "trust")}, nil
+}
+
+// trustStore returns the trust store repository hooks are checked against,
+// or nil if the check is disabled by the global config or GW_TRUST_ALL_HOOKS.
+// When no store can be located, an empty one is used so that hooks are still refused.
+func trustStore(cfg *config.Config) *hook.TrustStore {
+	if cfg.TrustAllHooks {
+		return nil
+	}
+	if v, err := strconv.ParseBool(os.Getenv(trustAllHooksEnv)); err == nil && v {
+		return nil
+	}
+	store, _ := hookTrustStore()
+	return &store
+}
+
+// globalHooksDir returns the directory of the user-global hooks, or "" if it cannot be determined.
+func globalHooksDir() string {
+	dir := config.GlobalDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "hooks")
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/gin0606/gw/internal/config"
+	"github.com/gin0606/gw/internal/git"
+	"github.com/gin0606/gw/internal/hook"
+)
+
+// HookTrust implements the "gw hook trust" command.
+// Without paths, every hook script of the current repository is trusted.
+// The trusted paths are printed to stdout.
+func HookTrust(paths []string) error {
+	paths, err := hookScriptPaths(paths)
+	if err != nil {
+		return err
+	}
+
+	store, err := hookTrustStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Trust(paths...); err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		fmt.Println(p)
+	}
+	return nil
+}
+
+// HookUntrust implements the "gw hook untrust" command.
+// Without paths, every hook script of the current repository is untrusted.
+// The untrusted paths are printed to stdout.
+func HookUntrust(paths []string) error {
+	paths, err := hookScriptPaths(paths)
+	if err != nil {
+		return err
+	}
+
+	store, err := hookTrustStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Untrust(paths...); err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		fmt.Println(p)
+	}
+	return nil
+}
+
+// hookScriptPaths returns paths as is, or all hook scripts of the current repository if paths is empty.
+// .gw/config is included when it defines inline hook commands, since they are trusted through it.
+func hookScriptPaths(paths []string) ([]string, error) {
+	if len(paths) > 0 {
+		return paths, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	scripts, err := hook.RepoScripts(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	configPath := filepath.Join(repoRoot, ".gw", "config")
+	for _, h := range cfg.Hooks {
+		if slices.ContainsFunc(h.Commands, func(c config.HookCommand) bool { return c.Source == configPath }) {
+			scripts = append(scripts, configPath)
+			break
+		}
+	}
+
+	if len(scripts) == 0 {
+		return nil, fmt.Errorf("no hooks found in .gw/hooks or .gw/config")
+	}
+	return scripts, nil
+}
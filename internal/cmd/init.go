@@ -49,9 +49,17 @@ func Init() error {
 # Working directory: repository root
 #
 # Available environment variables:
+#   GW_HOOK_NAME       - Name of this hook
 #   GW_REPO_ROOT       - Main repository root
+#   GW_REPO_NAME       - Repository name
 #   GW_WORKTREE_PATH   - Worktree path (to be created)
 #   GW_BRANCH          - Branch name
+#   GW_SANITIZED_NAME  - Branch name sanitized for use as a directory name
+#   GW_HEAD_SHA        - Commit the worktree will check out
+#   GW_BASE_REF        - Start point of a new branch (--from or origin/<default>); empty for an existing branch
+#   GW_BRANCH_CREATED  - "true" if a new branch is created, "false" otherwise
+#   GW_VERSION         - gw version
+#   GW_OUTPUT_FILE     - File to write key=value outputs to; they reach later hooks as GW_OUTPUT_<KEY>
 #
 # Exit non-zero to abort worktree creation.
 #
@@ -63,9 +71,17 @@ func Init() error {
 # Working directory: the new worktree
 #
 # Available environment variables:
+#   GW_HOOK_NAME       - Name of this hook
 #   GW_REPO_ROOT       - Main repository root
+#   GW_REPO_NAME       - Repository name
 #   GW_WORKTREE_PATH   - Worktree path
 #   GW_BRANCH          - Branch name
+#   GW_SANITIZED_NAME  - Branch name sanitized for use as a directory name
+#   GW_HEAD_SHA        - Commit checked out in the worktree
+#   GW_BASE_REF        - Start point of a new branch (--from or origin/<default>); empty for an existing branch
+#   GW_BRANCH_CREATED  - "true" if a new branch was created, "false" otherwise
+#   GW_VERSION         - gw version
+#   GW_OUTPUT_FILE     - File to write key=value outputs to; they reach later hooks as GW_OUTPUT_<KEY>
 #
 # Example: Install dependencies and copy files not tracked by git
 # npm install
@@ -76,9 +92,16 @@ func Init() error {
 # Working directory: the worktree being removed
 #
 # Available environment variables:
+#   GW_HOOK_NAME       - Name of this hook
 #   GW_REPO_ROOT       - Main repository root
+#   GW_REPO_NAME       - Repository name
 #   GW_WORKTREE_PATH   - Worktree path (to be removed)
 #   GW_BRANCH          - Branch name
+#   GW_SANITIZED_NAME  - Branch name sanitized for use as a directory name
+#   GW_HEAD_SHA        - HEAD commit of the worktree
+#   GW_FORCE           - "true" if --force was given, "false" otherwise
+#   GW_VERSION         - gw version
+#   GW_OUTPUT_FILE     - File to write key=value outputs to; they reach later hooks as GW_OUTPUT_<KEY>
 #
 # Exit non-zero to abort worktree removal (skipped with --force).
 #
@@ -90,9 +113,16 @@ func Init() error {
 # Working directory: repository root
 #
 # Available environment variables:
+#   GW_HOOK_NAME       - Name of this hook
 #   GW_REPO_ROOT       - Main repository root
+#   GW_REPO_NAME       - Repository name
 #   GW_WORKTREE_PATH   - Worktree path (already removed)
 #   GW_BRANCH          - Branch name
+#   GW_SANITIZED_NAME  - Branch name sanitized for use as a directory name
+#   GW_HEAD_SHA        - HEAD commit the worktree had
+#   GW_FORCE           - "true" if --force was given, "false" otherwise
+#   GW_VERSION         - gw version
+#   GW_OUTPUT_FILE     - File to write key=value outputs to; they reach later hooks as GW_OUTPUT_<KEY>
 #
 # Example: Fetch and delete the branch if it has been merged
 # git fetch --prune origin
@@ -102,14 +132,72 @@ func Init() error {
 # if git merge-base --is-ancestor "$GW_BRANCH" origin/main; then
 #   git branch -D "$GW_BRANCH"
 # fi
+`},
+		{"pre-move", `#!/bin/sh
+# This hook is called before "gw mv" renames a branch and moves its worktree.
+# Working directory: the worktree being moved (old path)
+#
+# Available environment variables:
+#   GW_HOOK_NAME          - Name of this hook
+#   GW_REPO_ROOT          - Main repository root
+#   GW_REPO_NAME          - Repository name
+#   GW_WORKTREE_PATH      - Worktree path (old path)
+#   GW_BRANCH             - Branch name (old name)
+#   GW_SANITIZED_NAME     - Branch name sanitized for use as a directory name
+#   GW_HEAD_SHA           - HEAD commit of the worktree
+#   GW_OLD_BRANCH         - Branch name before the rename
+#   GW_NEW_BRANCH         - Branch name after the rename
+#   GW_OLD_WORKTREE_PATH  - Worktree path before the move
+#   GW_NEW_WORKTREE_PATH  - Worktree path after the move
+#   GW_VERSION            - gw version
+#   GW_OUTPUT_FILE        - File to write key=value outputs to; they reach later hooks as GW_OUTPUT_<KEY>
+#
+# Exit non-zero to abort the move.
+#
+# Example: Stop development servers that hold the old path
+# docker compose down
+`},
+		{"post-move", `#!/bin/sh
+# This hook is called after "gw mv" renamed a branch and moved its worktree.
+# Working directory: the moved worktree (new path)
+#
+# Available environment variables:
+#   GW_HOOK_NAME          - Name of this hook
+#   GW_REPO_ROOT          - Main repository root
+#   GW_REPO_NAME          - Repository name
+#   GW_WORKTREE_PATH      - Worktree path (new path)
+#   GW_BRANCH             - Branch name (new name)
+#   GW_SANITIZED_NAME     - Branch name sanitized for use as a directory name
+#   GW_HEAD_SHA           - HEAD commit of the worktree
+#   GW_OLD_BRANCH         - Branch name before the rename
+#   GW_NEW_BRANCH         - Branch name after the rename
+#   GW_OLD_WORKTREE_PATH  - Worktree path before the move
+#   GW_NEW_WORKTREE_PATH  - Worktree path after the move
+#   GW_VERSION            - gw version
+#   GW_OUTPUT_FILE        - File to write key=value outputs to; they reach later hooks as GW_OUTPUT_<KEY>
+#
+# Example: Push the branch under its new name
+# git push -u origin "$GW_NEW_BRANCH"
 `},
 	}
 
+	var hookPaths []string
 	for _, h := range hooks {
-		if err := os.WriteFile(filepath.Join(hooksDir, h.name), []byte(h.content), 0755); err != nil {
+		hookPath := filepath.Join(hooksDir, h.name)
+		if err := os.WriteFile(hookPath, []byte(h.content), 0755); err != nil {
 			os.RemoveAll(gwDir)
 			return err
 		}
+		hookPaths = append(hookPaths, hookPath)
+	}
+
+	// The templates were written by the user's own command, so they start out trusted.
+	store, err := hookTrustStore()
+	if err == nil {
+		err = store.Trust(hookPaths...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gw: warning: failed to trust hook templates: %v\n", err)
 	}
 
 	fmt.Fprintf(os.Stderr, "Initialized .gw/ in %s\n", repoRoot)
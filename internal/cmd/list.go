@@ -1,14 +1,112 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"text/template"
 
 	"github.com/gin0606/gw/internal/git"
 )
 
+// ListOptions holds the output options for "gw list".
+type ListOptions struct {
+	JSON   bool
+	Format string // text/template applied to each ListEntry
+	Status bool
+}
+
+// ListEntry is a single worktree as rendered by "gw list".
+// Status is only populated with --status; Outputs holds the values declared by hooks through GW_OUTPUT_FILE.
+type ListEntry struct {
+	git.Worktree
+	Status  *WorktreeStatus   `json:"status,omitempty"`
+	Outputs map[string]string `json:"outputs,omitempty"`
+}
+
+// WorktreeStatus summarizes the state of a worktree for "gw list --status".
+type WorktreeStatus struct {
+	Dirty    bool   `json:"dirty"`
+	Upstream string `json:"upstream,omitempty"`
+	Gone     bool   `json:"gone"`
+	Ahead    int    `json:"ahead"`
+	Behind   int    `json:"behind"`
+	Merged   bool   `json:"merged"`
+}
+
+// statusConcurrency bounds the number of worktrees whose status is computed in parallel.
+const statusConcurrency = 8
+
+// formatEscapes expands the escape sequences accepted in --format so that
+// shell users can write '{{.Branch}}\t{{.Path}}' without $'...' quoting.
+var formatEscapes = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+// expandFormatEscapes applies formatEscapes to the text outside {{ }} actions only,
+// leaving string literals such as {{printf "%s\n" .Branch}} to the template parser.
+func expandFormatEscapes(format string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(format, "{{")
+		if start < 0 {
+			sb.WriteString(formatEscapes.Replace(format))
+			return sb.String()
+		}
+		sb.WriteString(formatEscapes.Replace(format[:start]))
+		end := actionEnd(format, start+2)
+		sb.WriteString(format[start:end])
+		format = format[end:]
+	}
+}
+
+// actionEnd returns the index just past the "}}" that closes the action starting at i,
+// skipping quoted strings and comments, or len(s) if the action is not closed.
+func actionEnd(s string, i int) int {
+	for i < len(s) {
+		switch c := s[i]; {
+		case c == '"' || c == '\'':
+			for i++; i < len(s) && s[i] != c; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		case c == '`':
+			if j := strings.IndexByte(s[i+1:], '`'); j >= 0 {
+				i += j + 1
+			} else {
+				return len(s)
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			if j := strings.Index(s[i+2:], "*/"); j >= 0 {
+				i += j + 3
+			} else {
+				return len(s)
+			}
+		case strings.HasPrefix(s[i:], "}}"):
+			return i + 2
+		}
+		i++
+	}
+	return len(s)
+}
+
 // List implements the "gw list" command.
-func List() error {
+func List(opts ListOptions) error {
+	if opts.JSON && opts.Format != "" {
+		return fmt.Errorf("--json and --format cannot be used together")
+	}
+
+	var tmpl *template.Template
+	if opts.Format != "" {
+		var err error
+		tmpl, err = template.New("format").Parse(expandFormatEscapes(opts.Format))
+		if err != nil {
+			return fmt.Errorf("invalid format template: %w", err)
+		}
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		return err
@@ -24,9 +122,147 @@ func List() error {
 		return err
 	}
 
-	for _, wt := range worktrees {
-		fmt.Println(wt.Path)
+	outputs, err := loadOutputs(repoRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gw: warning: failed to load hook outputs: %v\n", err)
+	}
+
+	entries := make([]ListEntry, len(worktrees))
+	for i, wt := range worktrees {
+		entries[i] = ListEntry{Worktree: wt, Outputs: outputs[wt.Path]}
+	}
+
+	if opts.Status {
+		mergeTarget, err := git.DefaultRef(repoRoot)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gw: warning: cannot determine merge target: %v\n", err)
+		}
+		fillStatus(repoRoot, entries, mergeTarget)
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if tmpl != nil {
+		for _, e := range entries {
+			var sb strings.Builder
+			if err := tmpl.Execute(&sb, e); err != nil {
+				return fmt.Errorf("failed to render format template: %w", err)
+			}
+			fmt.Println(sb.String())
+		}
+		return nil
+	}
+
+	if opts.Status {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%s\t%s\n", e.Path, formatStatus(e))
+		}
+		return tw.Flush()
+	}
+
+	for _, e := range entries {
+		fmt.Println(e.Path)
 	}
 
 	return nil
 }
+
+// fillStatus computes the status of each entry concurrently.
+// Merged is only computed when mergeTarget is non-empty.
+// Failures for individual worktrees are reported as warnings and leave the affected fields zero.
+func fillStatus(repoRoot string, entries []ListEntry, mergeTarget string) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, statusConcurrency)
+	warnings := make([][]error, len(entries))
+
+	for i := range entries {
+		if entries[i].Bare {
+			continue
+		}
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			entries[i].Status, warnings[i] = worktreeStatus(repoRoot, entries[i].Worktree, mergeTarget)
+		})
+	}
+	wg.Wait()
+
+	for i, errs := range warnings {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "gw: warning: %s: %v\n", entries[i].Path, err)
+		}
+	}
+}
+
+func worktreeStatus(repoRoot string, wt git.Worktree, mergeTarget string) (*WorktreeStatus, []error) {
+	st := &WorktreeStatus{}
+	var errs []error
+
+	if !wt.Prunable {
+		dirty, err := git.IsDirty(wt.Path)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		st.Dirty = dirty
+	}
+
+	if wt.Branch != "" {
+		up, err := git.BranchUpstream(repoRoot, wt.Branch)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		st.Upstream = up.Ref
+		st.Gone = up.Gone
+		st.Ahead = up.Ahead
+		st.Behind = up.Behind
+	}
+
+	if mergeTarget != "" && wt.Head != "" {
+		merged, err := git.IsMerged(repoRoot, wt.Branch, wt.Head, mergeTarget)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		st.Merged = merged
+	}
+
+	return st, errs
+}
+
+// formatStatus renders the status columns of the plain --status output.
+func formatStatus(e ListEntry) string {
+	branch := e.Branch
+	if branch == "" {
+		branch = "(detached)"
+	}
+	if e.Status == nil {
+		return branch + "\t-\t-\t-"
+	}
+
+	state := "clean"
+	switch {
+	case e.Prunable:
+		state = "missing"
+	case e.Status.Dirty:
+		state = "dirty"
+	}
+
+	tracking := "-"
+	switch {
+	case e.Status.Gone:
+		tracking = "gone"
+	case e.Status.Upstream != "":
+		tracking = fmt.Sprintf("+%d/-%d", e.Status.Ahead, e.Status.Behind)
+	}
+
+	merged := "-"
+	if e.Status.Merged {
+		merged = "merged"
+	}
+
+	return strings.Join([]string{branch, state, tracking, merged}, "\t")
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gin0606/gw/internal/config"
+	"github.com/gin0606/gw/internal/git"
+	"github.com/gin0606/gw/internal/hook"
+	"github.com/gin0606/gw/internal/pathutil"
+)
+
+// Move implements the "gw mv" command.
+// It renames oldBranch to newBranch and moves its worktree to the path computed for newBranch.
+func Move(ctx context.Context, oldBranch, newBranch string) error {
+	// 1. Detect repo root
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	// 2. Look up the worktree and validate the new branch name
+	worktrees, err := git.ListWorktrees(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	wt, found := findWorktreeByBranch(worktrees, oldBranch)
+	if !found {
+		return fmt.Errorf("branch %q is not checked out in any worktree", oldBranch)
+	}
+	if wt.Path == repoRoot {
+		return fmt.Errorf("cannot move the main worktree")
+	}
+	oldPath := wt.Path
+
+	exists, err := git.BranchExists(repoRoot, newBranch)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("branch '%s' already exists", newBranch)
+	}
+
+	// 3. Calculate the new worktree path
+	newPath, err := computeWorktreePath(repoRoot, newBranch)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureNoRunningHook(repoRoot, oldPath); err != nil {
+		return err
+	}
+
+	if newPath != oldPath {
+		cfg, err := config.Load(repoRoot)
+		if err != nil {
+			return err
+		}
+		newPath, err = resolvePathCollision(newPath, cfg.OnCollision, worktreeOwners(worktrees))
+		if err != nil {
+			return err
+		}
+		if err := pathutil.EnsureBaseDir(filepath.Dir(newPath)); err != nil {
+			return err
+		}
+	}
+
+	move := &hook.EventMove{
+		OldBranch:       oldBranch,
+		NewBranch:       newBranch,
+		OldWorktreePath: oldPath,
+		NewWorktreePath: newPath,
+	}
+
+	// 4. Run pre-move hook (in worktree directory, before anything changes)
+	if err := runHook(ctx, repoRoot, "pre-move", hookInput{
+		Dir:          oldPath,
+		WorktreePath: oldPath,
+		Branch:       oldBranch,
+		HeadSHA:      wt.Head,
+		Move:         move,
+	}); err != nil {
+		return fmt.Errorf("pre-move hook failed: %w", err)
+	}
+
+	// 5. Rename branch
+	if err := runGit(repoRoot, "branch", "-m", oldBranch, newBranch); err != nil {
+		return fmt.Errorf("git branch -m failed: %w", err)
+	}
+
+	// 6. Move worktree, rolling back the rename on failure
+	if newPath != oldPath {
+		// An empty directory reused by on_collision is replaced; "git worktree move" would move into it.
+		if info, err := os.Stat(newPath); err == nil && info.IsDir() {
+			if err := os.Remove(newPath); err != nil {
+				if rbErr := runGit(repoRoot, "branch", "-m", newBranch, oldBranch); rbErr != nil {
+					return fmt.Errorf("failed to remove %s: %w (rolling back branch rename also failed: %v)", newPath, err, rbErr)
+				}
+				return fmt.Errorf("failed to remove %s: %w", newPath, err)
+			}
+		}
+		if err := runGit(repoRoot, "worktree", "move", oldPath, newPath); err != nil {
+			if rbErr := runGit(repoRoot, "branch", "-m", newBranch, oldBranch); rbErr != nil {
+				return fmt.Errorf("git worktree move failed: %w (rolling back branch rename also failed: %v)", err, rbErr)
+			}
+			return fmt.Errorf("git worktree move failed: %w", err)
+		}
+		moveOutputs(repoRoot, oldPath, newPath)
+		moveHookJob(repoRoot, oldPath, newPath)
+	}
+
+	// 7. Run post-move hook (in the moved worktree directory)
+	if err := runHook(ctx, repoRoot, "post-move", hookInput{
+		Dir:          newPath,
+		WorktreePath: newPath,
+		Branch:       newBranch,
+		HeadSHA:      wt.Head,
+		Move:         move,
+	}); err != nil {
+		warnHookFailed("post-move", err)
+	}
+
+	// 8. Output new path to stdout
+	fmt.Println(newPath)
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/gin0606/gw/internal/git"
+)
+
+// worktreeOutputs maps a worktree path to the key/value outputs its hooks declared.
+// It is stored as JSON in <git-common-dir>/gw/outputs.json.
+type worktreeOutputs map[string]map[string]string
+
+func outputsPath(repoRoot string) (string, error) {
+	commonDir, err := git.CommonDir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, "gw", "outputs.json"), nil
+}
+
+// loadOutputs reads the stored hook outputs of all worktrees.
+func loadOutputs(repoRoot string) (worktreeOutputs, error) {
+	path, err := outputsPath(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := worktreeOutputs{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return outputs, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &outputs); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// updateOutputs applies fn to the stored hook outputs and writes them back.
+// Updates are serialized with a lock file, since hooks of several gw processes may record outputs at once.
+func updateOutputs(repoRoot string, fn func(worktreeOutputs)) error {
+	path, err := outputsPath(repoRoot)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	lock, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", lock.Name(), err)
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	outputs, err := loadOutputs(repoRoot)
+	if err != nil {
+		return err
+	}
+	fn(outputs)
+	return writeJSONFile(path, outputs)
+}
+
+// writeJSONFile atomically replaces the file at path with the JSON encoding of v.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// dropOutputs forgets the hook outputs of the worktrees at paths.
+// A failure only warns, since stale outputs never block a worktree operation.
+func dropOutputs(repoRoot string, paths ...string) {
+	err := updateOutputs(repoRoot, func(o worktreeOutputs) {
+		for _, p := range paths {
+			delete(o, p)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gw: warning: failed to update hook outputs: %v\n", err)
+	}
+}
+
+// moveOutputs re-keys the hook outputs of a worktree that moved from oldPath to newPath.
+func moveOutputs(repoRoot, oldPath, newPath string) {
+	err := updateOutputs(repoRoot, func(o worktreeOutputs) {
+		if v, ok := o[oldPath]; ok {
+			delete(o, oldPath)
+			o[newPath] = v
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gw: warning: failed to update hook outputs: %v\n", err)
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin0606/gw/internal/git"
+)
+
+// PassthroughCommands lists the git worktree subcommands that gw forwards unchanged.
+// add, list, prune and remove are wrapped by gw's own commands and keep their hook semantics.
+var PassthroughCommands = []string{"lock", "move", "repair", "unlock"}
+
+// Passthrough runs "git worktree <subcommand> <args...>" in the main repository root.
+// git's output is written to stderr, following gw's output convention.
+func Passthrough(subcommand string, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	args = absPathArgs(subcommand, args, cwd)
+	gitArgs := append([]string{"worktree", subcommand}, args...)
+
+	// Hook outputs and background hook state are keyed by worktree path and follow a moved worktree, as with "gw mv".
+	var before []git.Worktree
+	if subcommand == "move" {
+		before, err = git.ListWorktrees(repoRoot)
+		if err != nil {
+			return err
+		}
+		if wt, ok := findWorktreeArg(before, args); ok {
+			if err := ensureNoRunningHook(repoRoot, wt.Path); err != nil {
+				return err
+			}
+		}
+	}
+
+	gitCmd := exec.Command("git", gitArgs...)
+	gitCmd.Dir = repoRoot
+	gitCmd.Stdin = os.Stdin
+	gitCmd.Stdout = os.Stderr
+	gitCmd.Stderr = os.Stderr
+
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("git worktree %s failed: %w", subcommand, err)
+	}
+
+	if subcommand == "move" {
+		after, err := git.ListWorktrees(repoRoot)
+		if err != nil {
+			return err
+		}
+		if oldPath, newPath, ok := movedWorktree(before, after); ok {
+			moveOutputs(repoRoot, oldPath, newPath)
+			moveHookJob(repoRoot, oldPath, newPath)
+		}
+	}
+	return nil
+}
+
+// findWorktreeArg returns the worktree named by the first positional argument of "git worktree move":
+// its path, or a suffix of its path made of whole components.
+func findWorktreeArg(worktrees []git.Worktree, args []string) (git.Worktree, bool) {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		arg = strings.TrimSuffix(arg, "/")
+		for _, wt := range worktrees {
+			if wt.Path == arg || strings.HasSuffix(wt.Path, "/"+arg) {
+				return wt, true
+			}
+		}
+		break
+	}
+	return git.Worktree{}, false
+}
+
+// movedWorktree compares the worktrees before and after "git worktree move" and returns the old and new path
+// of the one that moved.
+func movedWorktree(before, after []git.Worktree) (oldPath, newPath string, ok bool) {
+	paths := func(wts []git.Worktree) map[string]bool {
+		m := map[string]bool{}
+		for _, wt := range wts {
+			m[wt.Path] = true
+		}
+		return m
+	}
+	beforePaths, afterPaths := paths(before), paths(after)
+	var removed, added []string
+	for p := range beforePaths {
+		if !afterPaths[p] {
+			removed = append(removed, p)
+		}
+	}
+	for p := range afterPaths {
+		if !beforePaths[p] {
+			added = append(added, p)
+		}
+	}
+	if len(removed) != 1 || len(added) != 1 {
+		return "", "", false
+	}
+	return removed[0], added[0], true
+}
+
+// absPathArgs rewrites path arguments that are relative to cwd into absolute
+// paths, since git runs in the main repository root rather than in cwd.
+// Arguments that do not exist relative to cwd are left alone so that worktrees
+// can still be identified by the unique last component of their path, except
+// for the destination of "move", which never exists yet.
+func absPathArgs(subcommand string, args []string, cwd string) []string {
+	out := make([]string, 0, len(args))
+	positional := 0
+	skipValue := false
+	for _, arg := range args {
+		switch {
+		case skipValue:
+			skipValue = false
+		case arg == "--reason":
+			skipValue = true
+		case strings.HasPrefix(arg, "-"):
+		default:
+			positional++
+			if filepath.IsAbs(arg) {
+				break
+			}
+			joined := filepath.Join(cwd, arg)
+			if _, err := os.Stat(joined); err == nil || (subcommand == "move" && positional == 2) {
+				arg = joined
+			}
+		}
+		out = append(out, arg)
+	}
+	return out
+}
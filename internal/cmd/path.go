@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gin0606/gw/internal/config"
+	"github.com/gin0606/gw/internal/git"
+	"github.com/gin0606/gw/internal/pathutil"
+)
+
+// ErrNoWorktree is returned by Path when existing is set and the branch has no worktree.
+var ErrNoWorktree = errors.New("no worktree for branch")
+
+// Path implements the "gw path" command.
+// It prints the path of the worktree that has branch checked out, or the path
+// "gw add" would create for it when there is none. With existing, a branch
+// without a worktree is an error wrapping ErrNoWorktree.
+func Path(branch string, existing bool) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	// The registered path wins: it may differ from the computed one if the config changed
+	worktrees, err := git.ListWorktrees(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	if wt, found := findWorktreeByBranch(worktrees, branch); found {
+		fmt.Println(wt.Path)
+		return nil
+	}
+
+	if existing {
+		return fmt.Errorf("%w: %s", ErrNoWorktree, branch)
+	}
+
+	wtPath, err := computeWorktreePath(repoRoot, branch)
+	if err != nil {
+		return err
+	}
+
+	// Apply on_collision like "gw add", quietly. When the collision cannot be resolved,
+	// "gw add" would fail; the computed path is still the answer.
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+	if resolved, err := pathutil.ResolveCollision(wtPath, cfg.OnCollision, worktreeOwners(worktrees)); err == nil {
+		wtPath = resolved
+	}
+
+	fmt.Println(wtPath)
+	return nil
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin0606/gw/internal/git"
+)
+
+// PruneOptions holds the options for "gw prune".
+// Without Merged or Gone, worktrees matching either criterion are candidates.
+type PruneOptions struct {
+	DryRun    bool
+	Merged    bool   // select worktrees merged into origin/<default>
+	Gone      bool   // select worktrees whose upstream branch is gone
+	OlderThan string // only select worktrees whose HEAD commit is older than this (e.g. "720h", "30d")
+}
+
+// Prune implements the "gw prune" command.
+// Selected worktrees are removed through the same hook pipeline as "gw rm".
+// Entries whose directories were deleted manually are always pruned with `git worktree prune`.
+func Prune(ctx context.Context, opts PruneOptions) error {
+	var maxAge time.Duration
+	if opts.OlderThan != "" {
+		var err error
+		maxAge, err = parseAge(opts.OlderThan)
+		if err != nil {
+			return err
+		}
+	}
+	selectMerged := opts.Merged || !opts.Gone
+	selectGone := opts.Gone || !opts.Merged
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	repoRoot, err := git.RepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	var mergeTarget string
+	if selectMerged {
+		mergeTarget, err = git.DefaultRef(repoRoot)
+		if err != nil {
+			if opts.Merged {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "gw: warning: cannot determine merge target; skipping merged check: %v\n", err)
+		}
+	}
+
+	worktrees, err := git.ListWorktrees(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	var entries []ListEntry
+	for _, wt := range worktrees {
+		if wt.Main || wt.Bare || wt.Locked {
+			continue
+		}
+		entries = append(entries, ListEntry{Worktree: wt})
+	}
+	fillStatus(repoRoot, entries, mergeTarget)
+
+	// 1. Select candidates
+	var candidates, missing []git.Worktree
+	for _, e := range entries {
+		if e.Prunable {
+			fmt.Fprintf(os.Stderr, "gw: %s (missing)\n", e.Path)
+			missing = append(missing, e.Worktree)
+			continue
+		}
+
+		var reasons []string
+		if selectMerged && e.Status.Merged {
+			reasons = append(reasons, "merged")
+		}
+		if selectGone && e.Status.Gone {
+			reasons = append(reasons, "gone")
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		if maxAge > 0 {
+			committed, err := git.CommitTime(repoRoot, e.Head)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "gw: warning: %s: %v\n", e.Path, err)
+				continue
+			}
+			if time.Since(committed) < maxAge {
+				continue
+			}
+		}
+
+		if e.Status.Dirty {
+			fmt.Fprintf(os.Stderr, "gw: warning: skipping %s (%s): worktree has uncommitted changes\n", e.Path, strings.Join(reasons, ", "))
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "gw: %s (%s)\n", e.Path, strings.Join(reasons, ", "))
+		candidates = append(candidates, e.Worktree)
+	}
+
+	if opts.DryRun {
+		for _, wt := range candidates {
+			fmt.Println(wt.Path)
+		}
+		for _, wt := range missing {
+			fmt.Println(wt.Path)
+		}
+		return nil
+	}
+
+	// 2. Remove selected worktrees
+	failed := 0
+	for _, wt := range candidates {
+		if err := removeWorktree(ctx, repoRoot, wt, RemoveOptions{}); err != nil {
+			fmt.Fprintf(os.Stderr, "gw: warning: failed to remove %s: %v\n", wt.Path, err)
+			failed++
+			continue
+		}
+		fmt.Println(wt.Path)
+	}
+
+	// 3. Prune administrative files of manually deleted worktrees
+	if len(missing) > 0 {
+		if err := runGit(repoRoot, "worktree", "prune"); err != nil {
+			return fmt.Errorf("git worktree prune failed: %w", err)
+		}
+		var paths []string
+		for _, wt := range missing {
+			paths = append(paths, wt.Path)
+		}
+		dropOutputs(repoRoot, paths...)
+		for _, wt := range missing {
+			fmt.Println(wt.Path)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d worktree(s)", failed)
+	}
+	return nil
+}
+
+// parseAge parses a duration for --older-than.
+// In addition to time.ParseDuration syntax, a plain number of days ("30d") is accepted.
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q for --older-than (e.g. \"720h\" or \"30d\")", s)
+	}
+	return d, nil
+}
@@ -1,27 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 
 	"github.com/gin0606/gw/internal/git"
-	"github.com/gin0606/gw/internal/hook"
 )
 
-// Remove implements the "gw rm" command.
-func Remove(path string, force bool) error {
-	// 1. Normalize path to absolute and resolve symlinks
-	wtPath, err := filepath.Abs(path)
-	if err != nil {
-		return err
-	}
-	if resolved, err := filepath.EvalSymlinks(wtPath); err == nil {
-		wtPath = resolved
-	}
+// RemoveOptions holds the options for "gw rm".
+type RemoveOptions struct {
+	Force        bool
+	ByBranch     bool // treat the argument as a branch name only
+	DeleteBranch bool // delete the local branch after the worktree is removed
+}
 
-	// 2. Detect repo root from current working directory
+// Remove implements the "gw rm" command.
+// target is a worktree path or, when it is not an existing path, the name of a branch checked out in a worktree.
+func Remove(ctx context.Context, target string, opts RemoveOptions) error {
+	// 1. Detect repo root from current working directory
 	cwd, err := os.Getwd()
 	if err != nil {
 		return err
@@ -31,39 +29,64 @@ func Remove(path string, force bool) error {
 		return err
 	}
 
-	// 3. Look up the worktree to get its branch name (needed for hooks)
+	// 2. Look up the worktree by path or branch (the branch name is needed for hooks)
 	worktrees, err := git.ListWorktrees(repoRoot)
 	if err != nil {
 		return err
 	}
 
-	var branch string
-	found := false
-	for _, wt := range worktrees {
-		if wt.Path == wtPath {
-			branch = wt.Branch
-			found = true
-			break
+	wt, err := resolveWorktree(worktrees, target, opts.ByBranch)
+	if err != nil {
+		return err
+	}
+	if wt.Path == repoRoot {
+		return fmt.Errorf("cannot remove the main worktree")
+	}
+
+	if opts.DeleteBranch {
+		if wt.Branch == "" {
+			return fmt.Errorf("worktree %s has no branch checked out; --delete-branch cannot be used", wt.Path)
+		}
+		if !opts.Force {
+			if err := checkBranchDeletable(repoRoot, wt.Branch); err != nil {
+				return err
+			}
 		}
 	}
-	if !found {
-		return fmt.Errorf("path %q is not a git worktree", wtPath)
+
+	return removeWorktree(ctx, repoRoot, wt, opts)
+}
+
+// removeWorktree removes a resolved worktree through the hook pipeline:
+// pre-remove hook, git worktree remove, optional branch deletion, and post-remove hook.
+// Prerequisite checks are the caller's responsibility.
+func removeWorktree(ctx context.Context, repoRoot string, wt git.Worktree, opts RemoveOptions) error {
+	wtPath := wt.Path
+	branch := wt.Branch
+	in := hookInput{
+		WorktreePath: wtPath,
+		Branch:       branch,
+		HeadSHA:      wt.Head,
+		Flags:        map[string]bool{"force": opts.Force},
 	}
-	if wtPath == repoRoot {
-		return fmt.Errorf("cannot remove the main worktree")
+
+	// A background post-add hook must not keep running in a removed worktree:
+	// wait for it to finish, or terminate it with --force.
+	if err := waitBackgroundHook(ctx, repoRoot, wtPath, opts.Force); err != nil {
+		return err
 	}
 
-	// Run pre-remove hook (in worktree directory)
-	if err := hook.Run(repoRoot, "pre-remove", wtPath, wtPath, branch, os.Stderr); err != nil {
-		if !force {
+	// 3. Run pre-remove hook (in worktree directory)
+	if err := runHook(ctx, repoRoot, "pre-remove", in.at(wtPath)); err != nil {
+		if !opts.Force {
 			return fmt.Errorf("pre-remove hook failed: %w", err)
 		}
-		fmt.Fprintf(os.Stderr, "gw: warning: pre-remove hook failed: %v\n", err)
+		warnHookFailed("pre-remove", err)
 	}
 
-	// 3. Remove worktree
+	// 4. Remove worktree
 	gitArgs := []string{"worktree", "remove"}
-	if force {
+	if opts.Force {
 		gitArgs = append(gitArgs, "--force")
 	}
 	gitArgs = append(gitArgs, wtPath)
@@ -77,9 +100,46 @@ func Remove(path string, force bool) error {
 		return fmt.Errorf("git worktree remove failed: %w", err)
 	}
 
-	// 4. Run post-remove hook (at repo root)
-	if err := hook.Run(repoRoot, "post-remove", repoRoot, wtPath, branch, os.Stderr); err != nil {
-		fmt.Fprintf(os.Stderr, "gw: warning: post-remove hook failed: %v\n", err)
+	// 5. Delete branch (before post-remove so the hook sees the final state)
+	var deleteErr error
+	if opts.DeleteBranch {
+		if err := runGit(repoRoot, "branch", "-D", branch); err != nil {
+			deleteErr = fmt.Errorf("git branch -D failed: %w", err)
+		}
+	}
+
+	// 6. Run post-remove hook (at repo root)
+	if err := runHook(ctx, repoRoot, "post-remove", in.at(repoRoot)); err != nil {
+		warnHookFailed("post-remove", err)
+	}
+	dropOutputs(repoRoot, wtPath)
+	dropHookJob(repoRoot, wtPath)
+
+	return deleteErr
+}
+
+// checkBranchDeletable returns an error if deleting branch could lose commits:
+// the branch must be merged into the default branch and must not be ahead of its upstream.
+func checkBranchDeletable(repoRoot, branch string) error {
+	defaultRef, err := git.DefaultRef(repoRoot)
+	if err != nil {
+		return fmt.Errorf("cannot verify that branch %q is merged: %w", branch, err)
+	}
+
+	merged, err := git.IsAncestor(repoRoot, branch, defaultRef)
+	if err != nil {
+		return err
+	}
+	if !merged {
+		return fmt.Errorf("branch %q is not merged into %s; use --force to delete it anyway", branch, defaultRef)
+	}
+
+	up, err := git.BranchUpstream(repoRoot, branch)
+	if err != nil {
+		return err
+	}
+	if up.Ref != "" && !up.Gone && up.Ahead > 0 {
+		return fmt.Errorf("branch %q has %d commit(s) not on its upstream %s; use --force to delete it anyway", branch, up.Ahead, up.Ref)
 	}
 
 	return nil
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// shellIntegrationEnv is set by the shell function emitted by "gw shell-init"
+// when it invokes the gw binary, so that gw can tell whether a cd will follow.
+const shellIntegrationEnv = "GW_SHELL_INTEGRATION"
+
+const shellInitPOSIX = `# gw shell integration: eval "$(gw shell-init %[1]s)"
+gw() {
+  local __gw_arg __gw_dir
+  for __gw_arg in "$@"; do
+    if [ "$__gw_arg" = "--generate-shell-completion" ]; then
+      command gw "$@"
+      return
+    fi
+  done
+  case "$1" in
+    cd)
+      __gw_dir="$(GW_SHELL_INTEGRATION=1 command gw "$@")" || return
+      builtin cd -- "$__gw_dir"
+      ;;
+    add)
+      for __gw_arg in "$@"; do
+        if [ "$__gw_arg" = "--cd" ]; then
+          __gw_dir="$(GW_SHELL_INTEGRATION=1 command gw "$@")" || return
+          builtin cd -- "$__gw_dir"
+          return
+        fi
+      done
+      command gw "$@"
+      ;;
+    *)
+      command gw "$@"
+      ;;
+  esac
+}
+`
+
+const shellInitFish = `# gw shell integration: gw shell-init fish | source
+function gw
+    if contains -- --generate-shell-completion $argv
+        command gw $argv
+        return
+    end
+    switch "$argv[1]"
+        case cd
+            set -l dir (env GW_SHELL_INTEGRATION=1 command gw $argv); or return
+            builtin cd -- $dir
+        case add
+            if contains -- --cd $argv
+                set -l dir (env GW_SHELL_INTEGRATION=1 command gw $argv); or return
+                builtin cd -- $dir
+            else
+                command gw $argv
+            end
+        case '*'
+            command gw $argv
+    end
+end
+`
+
+// ShellInit implements the "gw shell-init" command.
+// It prints a shell function wrapping gw so that "gw cd" and "gw add --cd"
+// can change the directory of the calling shell.
+func ShellInit(shell string) error {
+	switch shell {
+	case "bash", "zsh":
+		fmt.Printf(shellInitPOSIX, shell)
+	case "fish":
+		fmt.Print(shellInitFish)
+	default:
+		return fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", shell)
+	}
+	return nil
+}
+
+// WarnWithoutShellIntegration prints a warning when a directory change was
+// requested but gw is not running under the shell function from "gw shell-init".
+func WarnWithoutShellIntegration(what string) {
+	if os.Getenv(shellIntegrationEnv) != "" {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "gw: warning: %s requires shell integration to change directory; add 'eval \"$(gw shell-init bash)\"' (or zsh/fish) to your shell config\n", what)
+}
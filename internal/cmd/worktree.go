@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin0606/gw/internal/config"
+	"github.com/gin0606/gw/internal/git"
+	"github.com/gin0606/gw/internal/pathutil"
+)
+
+// computeWorktreePath returns the absolute worktree path that "gw add" uses for branch,
+// based on worktrees_dir, path_template and sanitize in .gw/config and the rule matching branch.
+func computeWorktreePath(repoRoot, branch string) (string, error) {
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	cfg = cfg.ForBranch(branch)
+
+	repoName := git.RepoName(repoRoot)
+	baseDir, err := filepath.Abs(pathutil.BaseDir(repoRoot, repoName, cfg.WorktreesDir))
+	if err != nil {
+		return "", err
+	}
+
+	tmpl := cfg.PathTemplate
+	if tmpl == "" {
+		tmpl = pathutil.DefaultPathTemplate
+	}
+	sanitizer := cfg.Sanitizer()
+	return pathutil.RenderPath(baseDir, tmpl, pathutil.NewPathVars(repoName, branch, sanitizer), sanitizer)
+}
+
+// worktreeOwners maps the paths of worktrees to their branches, for pathutil.ValidatePath.
+func worktreeOwners(worktrees []git.Worktree) map[string]string {
+	owners := make(map[string]string, len(worktrees))
+	for _, wt := range worktrees {
+		owners[wt.Path] = wt.Branch
+	}
+	return owners
+}
+
+// resolvePathCollision applies the on_collision policy to the computed worktree path
+// and tells the user when the worktree is created somewhere else or in an existing directory.
+func resolvePathCollision(path, policy string, owners map[string]string) (string, error) {
+	resolved, err := pathutil.ResolveCollision(path, policy, owners)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case resolved != path:
+		if occ := pathutil.Occupant(path, owners); occ != "" {
+			fmt.Fprintf(os.Stderr, "gw: %s is %s; using %s\n", path, occ, resolved)
+		} else {
+			fmt.Fprintf(os.Stderr, "gw: %s already exists; using %s\n", path, resolved)
+		}
+	case pathutil.ValidatePath(path, owners) != nil:
+		fmt.Fprintf(os.Stderr, "gw: reusing empty directory %s\n", path)
+	}
+	return resolved, nil
+}
+
+// findWorktreeByPath returns the worktree registered at path.
+func findWorktreeByPath(worktrees []git.Worktree, path string) (git.Worktree, bool) {
+	for _, wt := range worktrees {
+		if wt.Path == path {
+			return wt, true
+		}
+	}
+	return git.Worktree{}, false
+}
+
+// findWorktreeByBranch returns the worktree that has branch checked out.
+func findWorktreeByBranch(worktrees []git.Worktree, branch string) (git.Worktree, bool) {
+	for _, wt := range worktrees {
+		if wt.Branch != "" && wt.Branch == branch {
+			return wt, true
+		}
+	}
+	return git.Worktree{}, false
+}
+
+// absPath normalizes path to an absolute path and resolves symlinks when the path exists.
+func absPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	return abs, nil
+}
+
+// worktreeContaining returns the worktree that contains dir.
+// When worktrees are nested, the innermost one is returned.
+func worktreeContaining(worktrees []git.Worktree, dir string) (git.Worktree, error) {
+	dir, err := absPath(dir)
+	if err != nil {
+		return git.Worktree{}, err
+	}
+	var found git.Worktree
+	for _, wt := range worktrees {
+		if dir != wt.Path && !strings.HasPrefix(dir, wt.Path+string(filepath.Separator)) {
+			continue
+		}
+		if len(wt.Path) > len(found.Path) {
+			found = wt
+		}
+	}
+	if found.Path == "" {
+		return git.Worktree{}, fmt.Errorf("%s is not inside a worktree", dir)
+	}
+	return found, nil
+}
+
+// resolveWorktree finds the worktree designated by target, which is either a
+// worktree path or the name of a branch checked out in a worktree.
+// With byBranch, target is only matched against branch names.
+// A target that names both a path and a different worktree's branch is rejected as ambiguous.
+func resolveWorktree(worktrees []git.Worktree, target string, byBranch bool) (git.Worktree, error) {
+	byBranchWt, branchFound := findWorktreeByBranch(worktrees, target)
+	if byBranch {
+		if !branchFound {
+			return git.Worktree{}, fmt.Errorf("branch %q is not checked out in any worktree", target)
+		}
+		return byBranchWt, nil
+	}
+
+	path, err := absPath(target)
+	if err != nil {
+		return git.Worktree{}, err
+	}
+	byPathWt, pathFound := findWorktreeByPath(worktrees, path)
+	_, statErr := os.Stat(path)
+	pathExists := pathFound || statErr == nil
+
+	if pathExists && branchFound && (!pathFound || byPathWt.Path != byBranchWt.Path) {
+		return git.Worktree{}, fmt.Errorf("%q is ambiguous: it is both a path (%s) and a branch checked out at %s; use --branch for the branch or ./%s for the path", target, path, byBranchWt.Path, target)
+	}
+
+	switch {
+	case pathFound:
+		return byPathWt, nil
+	case pathExists:
+		return git.Worktree{}, fmt.Errorf("path %q is not a git worktree", path)
+	case branchFound:
+		return byBranchWt, nil
+	default:
+		return git.Worktree{}, fmt.Errorf("%q is not a git worktree path or a branch checked out in a worktree", target)
+	}
+}
+
+// runGit runs a git command in dir, sending its output to stderr.
+func runGit(dir string, args ...string) error {
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = dir
+	gitCmd.Stdout = os.Stderr
+	gitCmd.Stderr = os.Stderr
+	return gitCmd.Run()
+}
@@ -0,0 +1,289 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gin0606/gw/internal/pathutil"
+)
+
+// Problem is something wrong with a config file, as reported by Check.
+type Problem struct {
+	File    string
+	Key     string // empty for problems with the whole file
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Key == "" {
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.File, p.Key, p.Message)
+}
+
+// Check validates the global config file and the repository's .gw/config strictly.
+// Besides the errors Load reports, it reports unknown keys, settings that are ignored
+// in the file they appear in, worktree directories inside the repository's metadata
+// and path templates that do not produce a path inside the worktree directory.
+// hooks are the known hook names, against which [hooks.<name>] and disable_hooks are checked.
+// Missing files are skipped. Load itself stays lenient.
+func Check(repoRoot string, hooks []string) ([]Problem, error) {
+	var problems []Problem
+	if dir := GlobalDir(); dir != "" {
+		p, err := checkFile(filepath.Join(dir, "config"), repoRoot, hooks, true)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, p...)
+	}
+	p, err := checkFile(filepath.Join(repoRoot, ".gw", "config"), repoRoot, hooks, false)
+	if err != nil {
+		return nil, err
+	}
+	return append(problems, p...), nil
+}
+
+func checkFile(path, repoRoot string, hooks []string, global bool) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var problems []Problem
+	report := func(key, format string, args ...any) {
+		problems = append(problems, Problem{File: path, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Syntax errors stop everything else; type errors are reported per key where possible.
+	var raw map[string]any
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		report("", "%v", err)
+		return problems, nil
+	}
+	for _, p := range checkTypes(raw) {
+		report(p[0], "%s", p[1])
+	}
+	var layer Config
+	md, err := toml.Decode(string(data), &layer)
+	if err != nil {
+		if len(problems) == 0 {
+			report("", "%v", err)
+		}
+		return problems, nil
+	}
+
+	for _, key := range md.Keys() {
+		if msg := checkKey(key, hooks); msg != "" {
+			report(key.String(), "%s", msg)
+		}
+	}
+	if !global && md.IsDefined("trust_all_hooks") {
+		report("trust_all_hooks", "only honored in the global config file; ignored here")
+	}
+
+	// The same validation as Load, e.g. of enumerations and negative durations.
+	cfg := &Config{sources: map[string]string{}}
+	if err := cfg.merge(path, global); err != nil {
+		report("", "%s", strings.TrimPrefix(err.Error(), "invalid "+path+": "))
+		return problems, nil
+	}
+
+	repoName := filepath.Base(repoRoot)
+	checkPaths := func(key, worktreesDir, tmpl string) {
+		baseDir := pathutil.BaseDir(repoRoot, repoName, worktreesDir)
+		for _, reserved := range []string{".git", ".gw"} {
+			dir := filepath.Join(repoRoot, reserved)
+			if baseDir == dir || strings.HasPrefix(baseDir, dir+string(filepath.Separator)) {
+				report(key+"worktrees_dir", "resolves to %s, inside the repository's %s directory", baseDir, reserved)
+			}
+		}
+		if baseDir == filepath.Clean(repoRoot) {
+			report(key+"worktrees_dir", "resolves to the repository root %s", baseDir)
+		}
+		if tmpl != "" {
+			// A branch with several components shows templates that escape through "..".
+			vars := pathutil.NewPathVars(repoName, "feature/example", cfg.Sanitizer())
+			if _, err := pathutil.RenderPath(baseDir, tmpl, vars, cfg.Sanitizer()); err != nil {
+				report(key+"path_template", "%v", err)
+			}
+		}
+	}
+	if md.IsDefined("worktrees_dir") || md.IsDefined("path_template") {
+		checkPaths("", cfg.WorktreesDir, cfg.PathTemplate)
+	}
+	for _, r := range cfg.Rules {
+		for _, name := range r.DisableHooks {
+			if !slices.Contains(hooks, name) {
+				report(fmt.Sprintf("rules[%d].disable_hooks", r.Index), "unknown hook %q", name)
+			}
+		}
+		if r.WorktreesDir != "" || r.PathTemplate != "" {
+			checkPaths(fmt.Sprintf("rules[%d].", r.Index), r.WorktreesDir, r.PathTemplate)
+		}
+	}
+
+	return problems, nil
+}
+
+// checkTypes returns the known keys in raw whose values have the wrong type, with the reason.
+// Unknown keys are left to checkKey.
+func checkTypes(raw map[string]any) [][2]string {
+	var problems [][2]string
+	check := func(key string, v any, k kind) {
+		if msg := typeMismatch(v, k); msg != "" {
+			problems = append(problems, [2]string{key, msg})
+		}
+	}
+
+	for _, k := range scalarKeys {
+		table, name := splitKey(k.key)
+		m := raw
+		if table != "" {
+			m, _ = raw[table].(map[string]any)
+		}
+		if v, ok := m[name]; ok {
+			check(k.key, v, k.kind)
+		}
+	}
+
+	hooks, _ := raw["hooks"].(map[string]any)
+	for _, name := range slices.Sorted(maps.Keys(hooks)) {
+		switch h := hooks[name].(type) {
+		case []any:
+			check("hooks."+name, h, kindList)
+		case map[string]any:
+			for _, k := range hookKeys {
+				if v, ok := h[k.key]; ok {
+					check("hooks."+name+"."+k.key, v, k.kind)
+				}
+			}
+		default:
+			problems = append(problems, [2]string{"hooks." + name, fmt.Sprintf("expected a table or an array of commands, got %s", tomlType(h))})
+		}
+	}
+	return problems
+}
+
+// typeMismatch returns why v cannot be a value of kind k, or "" if it can.
+func typeMismatch(v any, k kind) string {
+	switch k {
+	case kindString:
+		if _, ok := v.(string); !ok {
+			return "expected a string, got " + tomlType(v)
+		}
+	case kindBool:
+		if _, ok := v.(bool); !ok {
+			return "expected true or false, got " + tomlType(v)
+		}
+	case kindInt:
+		if _, ok := v.(int64); !ok {
+			return "expected an integer, got " + tomlType(v)
+		}
+	case kindDuration:
+		if _, ok := v.(string); !ok {
+			return fmt.Sprintf("expected a duration string such as \"10m\", got %s %v", tomlType(v), v)
+		}
+	case kindList:
+		list, ok := v.([]any)
+		if !ok {
+			return "expected an array of strings, got " + tomlType(v)
+		}
+		for _, e := range list {
+			if _, ok := e.(string); !ok {
+				return "expected an array of strings, got an element of type " + tomlType(e)
+			}
+		}
+	}
+	return ""
+}
+
+// tomlType names the TOML type of a decoded value.
+func tomlType(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int64:
+		return "integer"
+	case float64:
+		return "float"
+	case []any, []map[string]any:
+		return "array"
+	case map[string]any:
+		return "table"
+	default:
+		return "date-time"
+	}
+}
+
+// checkKey returns why key is not a known config key, or "" if it is.
+func checkKey(key toml.Key, hooks []string) string {
+	var candidates []string
+	switch {
+	case len(key) == 1:
+		candidates = []string{"sanitize", "hooks", "rules"}
+		for _, k := range scalarKeys {
+			if !strings.Contains(k.key, ".") {
+				candidates = append(candidates, k.key)
+			}
+		}
+	case key[0] == "sanitize" && len(key) == 2:
+		candidates = []string{"strategy", "max_length"}
+	case key[0] == "hooks" && len(key) == 2:
+		candidates = hooks
+	case key[0] == "hooks" && len(key) == 3:
+		for _, k := range hookKeys {
+			candidates = append(candidates, k.key)
+		}
+	case key[0] == "rules" && len(key) == 2:
+		candidates = ruleKeys
+	default:
+		return "unknown key"
+	}
+
+	what := "key"
+	if key[0] == "hooks" && len(key) == 2 {
+		what = "hook"
+	}
+	name := key[len(key)-1]
+	if slices.Contains(candidates, name) {
+		return ""
+	}
+	for _, c := range candidates {
+		if editDistance(name, c) <= 2 {
+			return fmt.Sprintf("unknown %s (did you mean %q?)", what, c)
+		}
+	}
+	return "unknown " + what
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -3,35 +3,401 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/gin0606/gw/internal/pathutil"
 )
 
-// Config represents the .gw/config file.
+// Config is the merged configuration of the user-global config file
+// ($XDG_CONFIG_HOME/gw/config) and the repository's .gw/config.
+// Repository settings override global ones.
 type Config struct {
-	WorktreesDir string `toml:"worktrees_dir"`
+	WorktreesDir string                `toml:"worktrees_dir"`
+	PathTemplate string                `toml:"path_template"` // worktree path relative to WorktreesDir; see pathutil.RenderPath
+	Sanitize     SanitizeConfig        `toml:"sanitize"`
+	OnCollision  string                `toml:"on_collision"` // one of pathutil.CollisionPolicies; empty means "error"
+	Hooks        map[string]HookConfig `toml:"hooks"`        // keyed by hook name, e.g. "post-add"
+	Rules        []Rule                `toml:"rules"`        // repository rules first, then global ones; see RuleFor
+
+	// TrustAllHooks disables the hook trust check. It is only honored in the global
+	// config file, so that a repository cannot trust its own hooks.
+	TrustAllHooks bool `toml:"trust_all_hooks"`
+
+	sources map[string]string // key path (e.g. "hooks.post-add.timeout") -> file that set it
+	rule    *Rule             // rule applied by ForBranch
+}
+
+// Rule overrides settings for the branches it matches. It is written as
+//
+//	[[rules]]
+//	branch = "release/*"
+//	worktrees_dir = "/mnt/big/worktrees"
+//
+// Empty settings are not overridden.
+type Rule struct {
+	Branch       string   `toml:"branch"`        // glob matched against the whole branch name (path.Match syntax)
+	BranchRegex  string   `toml:"branch_regex"`  // regular expression; set instead of Branch
+	WorktreesDir string   `toml:"worktrees_dir"` // absolute or relative to the repository root
+	PathTemplate string   `toml:"path_template"`
+	From         string   `toml:"from"`          // start point of new branches instead of origin/<default branch>
+	DisableHooks []string `toml:"disable_hooks"` // hooks not run automatically for the branch
+
+	Source string `toml:"-"` // config file that defines the rule
+	Index  int    `toml:"-"` // position among the rules of Source, from 0
+
+	re *regexp.Regexp
+}
+
+// Name identifies the rule in messages, e.g. "rules[1] in /repo/.gw/config".
+func (r *Rule) Name() string {
+	return fmt.Sprintf("rules[%d] in %s", r.Index, r.Source)
+}
+
+// Pattern returns the branch pattern of the rule as written, e.g. `branch = "release/*"`.
+func (r *Rule) Pattern() string {
+	if r.BranchRegex != "" {
+		return fmt.Sprintf("branch_regex = %q", r.BranchRegex)
+	}
+	return fmt.Sprintf("branch = %q", r.Branch)
+}
+
+// Matches reports whether the rule applies to branch.
+func (r *Rule) Matches(branch string) bool {
+	if r.re != nil {
+		return r.re.MatchString(branch)
+	}
+	ok, _ := path.Match(r.Branch, branch)
+	return ok
+}
+
+func (r *Rule) compile() error {
+	switch {
+	case r.Branch == "" && r.BranchRegex == "":
+		return fmt.Errorf("one of branch or branch_regex is required")
+	case r.Branch != "" && r.BranchRegex != "":
+		return fmt.Errorf("branch and branch_regex cannot be used together")
+	case r.BranchRegex != "":
+		re, err := regexp.Compile(r.BranchRegex)
+		if err != nil {
+			return fmt.Errorf("invalid branch_regex: %w", err)
+		}
+		r.re = re
+	default:
+		if _, err := path.Match(r.Branch, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern %q: %w", r.Branch, err)
+		}
+	}
+	return nil
+}
+
+// SanitizeConfig selects how branch names are turned into directory names.
+type SanitizeConfig struct {
+	Strategy  string `toml:"strategy"`   // one of pathutil.Strategies; empty means "flatten"
+	MaxLength int    `toml:"max_length"` // maximum length of each directory name; zero means no limit
+}
+
+// HookConfig holds the settings of a single hook phase. It is written either as a table
+//
+//	[hooks.post-add]
+//	timeout = "10m"
+//	commands = ["npm ci"]
+//
+// or, for commands only, as an array: [hooks] post-add = ["npm ci"].
+type HookConfig struct {
+	Timeout time.Duration // e.g. "10m"; zero means no timeout
+	Async   bool          // run in the background without blocking the command; post-add only
+
+	// Commands are run with "sh -c". Commands of the global config file come before
+	// those of .gw/config; each remembers the file it was defined in.
+	Commands []HookCommand
+}
+
+// HookCommand is a hook command defined inline in a config file.
+type HookCommand struct {
+	Run    string // shell command line
+	Source string // config file that defined the command
+	Global bool   // defined in the global config file
+}
+
+// UnmarshalTOML decodes both the table and the array form of a hook phase.
+func (h *HookConfig) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case []any:
+		return h.decodeCommands(v)
+	case map[string]any:
+		if t, ok := v["timeout"]; ok {
+			switch t := t.(type) {
+			case string:
+				d, err := time.ParseDuration(t)
+				if err != nil {
+					return fmt.Errorf("invalid timeout: %w", err)
+				}
+				h.Timeout = d
+			default:
+				// A bare number has no unit; it is rejected rather than read as nanoseconds.
+				return fmt.Errorf("invalid timeout: expected a duration string such as \"10m\"")
+			}
+		}
+		if a, ok := v["async"]; ok {
+			async, ok := a.(bool)
+			if !ok {
+				return fmt.Errorf("async must be a boolean")
+			}
+			h.Async = async
+		}
+		if c, ok := v["commands"]; ok {
+			list, ok := c.([]any)
+			if !ok {
+				return fmt.Errorf("commands must be an array of strings")
+			}
+			return h.decodeCommands(list)
+		}
+		return nil
+	default:
+		return fmt.Errorf("expected a table or an array of commands")
+	}
+}
+
+func (h *HookConfig) decodeCommands(list []any) error {
+	for _, c := range list {
+		run, ok := c.(string)
+		if !ok {
+			return fmt.Errorf("commands must be an array of strings")
+		}
+		h.Commands = append(h.Commands, HookCommand{Run: run})
+	}
+	return nil
+}
+
+// GlobalDir returns the user-global gw directory: $XDG_CONFIG_HOME/gw, or ~/.config/gw.
+// Returns "" if neither XDG_CONFIG_HOME nor the home directory is known.
+func GlobalDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "gw")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gw")
+}
+
+// DataDir returns the directory for gw's persistent data: $XDG_DATA_HOME/gw, or ~/.local/share/gw.
+// Returns "" if neither XDG_DATA_HOME nor the home directory is known.
+func DataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "gw")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "gw")
 }
 
-// Load reads and parses .gw/config from the repository root.
-// Returns default config if the file does not exist.
+// Load reads the global config file and .gw/config from the repository root, in that order,
+// and merges them. Missing files are skipped; with neither present, the default config is returned.
 func Load(repoRoot string) (*Config, error) {
-	configPath := filepath.Join(repoRoot, ".gw", "config")
+	cfg := &Config{sources: map[string]string{}}
+
+	if dir := GlobalDir(); dir != "" {
+		if err := cfg.merge(filepath.Join(dir, "config"), true); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.merge(filepath.Join(repoRoot, ".gw", "config"), false); err != nil {
+		return nil, err
+	}
 
-	cfg := &Config{}
+	return cfg, nil
+}
 
-	data, err := os.ReadFile(configPath)
+// merge decodes the config file at path and overrides the keys it defines.
+// Keys that are only honored globally are ignored when global is false.
+func (c *Config) merge(path string, global bool) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return cfg, nil
+			return nil
 		}
-		return nil, err
+		return err
 	}
 
-	if _, err := toml.Decode(string(data), cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse .gw/config: %w", err)
+	var layer Config
+	md, err := toml.Decode(string(data), &layer)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
 	}
 
-	return cfg, nil
+	if md.IsDefined("worktrees_dir") {
+		c.WorktreesDir = layer.WorktreesDir
+		c.sources["worktrees_dir"] = path
+	}
+
+	if md.IsDefined("path_template") {
+		c.PathTemplate = layer.PathTemplate
+		c.sources["path_template"] = path
+	}
+
+	if md.IsDefined("sanitize", "strategy") {
+		if !slices.Contains(pathutil.Strategies, layer.Sanitize.Strategy) {
+			return fmt.Errorf("invalid %s: unknown sanitize.strategy %q (valid: %s)", path, layer.Sanitize.Strategy, strings.Join(pathutil.Strategies, ", "))
+		}
+		c.Sanitize.Strategy = layer.Sanitize.Strategy
+		c.sources["sanitize.strategy"] = path
+	}
+
+	if md.IsDefined("sanitize", "max_length") {
+		if layer.Sanitize.MaxLength < 0 {
+			return fmt.Errorf("invalid %s: sanitize.max_length must not be negative", path)
+		}
+		c.Sanitize.MaxLength = layer.Sanitize.MaxLength
+		c.sources["sanitize.max_length"] = path
+	}
+
+	if md.IsDefined("on_collision") {
+		if !slices.Contains(pathutil.CollisionPolicies, layer.OnCollision) {
+			return fmt.Errorf("invalid %s: unknown on_collision %q (valid: %s)", path, layer.OnCollision, strings.Join(pathutil.CollisionPolicies, ", "))
+		}
+		c.OnCollision = layer.OnCollision
+		c.sources["on_collision"] = path
+	}
+
+	if global && md.IsDefined("trust_all_hooks") {
+		c.TrustAllHooks = layer.TrustAllHooks
+		c.sources["trust_all_hooks"] = path
+	}
+
+	// Rules of a later file (the repository) are checked before those of an earlier one (global).
+	for i := range layer.Rules {
+		r := &layer.Rules[i]
+		if err := r.compile(); err != nil {
+			return fmt.Errorf("invalid %s: rules[%d]: %w", path, i, err)
+		}
+		r.Source = path
+		r.Index = i
+	}
+	c.Rules = append(layer.Rules, c.Rules...)
+
+	for name, h := range layer.Hooks {
+		if c.Hooks == nil {
+			c.Hooks = map[string]HookConfig{}
+		}
+		merged := c.Hooks[name]
+
+		if md.IsDefined("hooks", name, "timeout") {
+			if h.Timeout < 0 {
+				return fmt.Errorf("invalid %s: hooks.%s.timeout must not be negative", path, name)
+			}
+			merged.Timeout = h.Timeout
+			c.sources["hooks."+name+".timeout"] = path
+		}
+
+		if md.IsDefined("hooks", name, "async") {
+			if h.Async && name != "post-add" {
+				return fmt.Errorf("invalid %s: hooks.%s.async is only supported for post-add", path, name)
+			}
+			merged.Async = h.Async
+			c.sources["hooks."+name+".async"] = path
+		}
+
+		// Commands accumulate instead of overriding, like hook scripts in the global and repository directories.
+		for _, cmd := range h.Commands {
+			cmd.Source = path
+			cmd.Global = global
+			merged.Commands = append(merged.Commands, cmd)
+		}
+
+		c.Hooks[name] = merged
+	}
+
+	return nil
+}
+
+// Source returns the path of the config file that set key (e.g. "worktrees_dir"
+// or "hooks.post-add.timeout"), or "" if the key has its default value.
+func (c *Config) Source(key string) string {
+	return c.sources[key]
+}
+
+// Sanitizer returns the sanitizer for branch names configured by the sanitize table.
+func (c *Config) Sanitizer() pathutil.Sanitizer {
+	return pathutil.Sanitizer{Strategy: c.Sanitize.Strategy, MaxLength: c.Sanitize.MaxLength}
+}
+
+// RuleFor returns the first rule that matches branch, or nil if none does.
+// Detached worktrees (an empty branch) match no rule.
+func (c *Config) RuleFor(branch string) *Rule {
+	if branch == "" {
+		return nil
+	}
+	for i := range c.Rules {
+		if c.Rules[i].Matches(branch) {
+			return &c.Rules[i]
+		}
+	}
+	return nil
+}
+
+// ForBranch returns the config in effect for branch: a copy of c with the settings of
+// the matching rule applied. Source reports the rule's file for the overridden keys.
+func (c *Config) ForBranch(branch string) *Config {
+	r := c.RuleFor(branch)
+	if r == nil {
+		return c
+	}
+
+	eff := *c
+	eff.rule = r
+	eff.sources = maps.Clone(c.sources)
+	if r.WorktreesDir != "" {
+		eff.WorktreesDir = r.WorktreesDir
+		eff.sources["worktrees_dir"] = r.Source
+	}
+	if r.PathTemplate != "" {
+		eff.PathTemplate = r.PathTemplate
+		eff.sources["path_template"] = r.Source
+	}
+	return &eff
+}
+
+// Rule returns the rule applied by ForBranch, or nil.
+func (c *Config) Rule() *Rule {
+	return c.rule
+}
+
+// StartRef returns the start point for new branches set by the applied rule, or "" for the default.
+func (c *Config) StartRef() string {
+	if c.rule == nil {
+		return ""
+	}
+	return c.rule.From
+}
+
+// HookEnabled reports whether a hook runs automatically; the applied rule can disable it.
+func (c *Config) HookEnabled(name string) bool {
+	return c.rule == nil || !slices.Contains(c.rule.DisableHooks, name)
+}
+
+// HookCommands returns the inline commands of a hook, global ones first.
+func (c *Config) HookCommands(name string) []HookCommand {
+	return c.Hooks[name].Commands
+}
+
+// HookAsync reports whether a hook is configured to run in the background.
+func (c *Config) HookAsync(name string) bool {
+	return c.Hooks[name].Async
+}
+
+// HookTimeout returns the configured timeout of a hook, or zero if none is set.
+func (c *Config) HookTimeout(name string) time.Duration {
+	return c.Hooks[name].Timeout
 }
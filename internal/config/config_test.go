@@ -3,11 +3,28 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin0606/gw/internal/config"
+	"github.com/gin0606/gw/internal/pathutil"
 )
 
+func TestMain(m *testing.M) {
+	// Keep the developer's own global config out of the tests.
+	dir, err := os.MkdirTemp("", "gw-config-home")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestLoad_NoConfigFile(t *testing.T) {
 	dir := t.TempDir()
 
@@ -83,3 +100,723 @@ func writeConfig(t *testing.T, repoRoot, content string) {
 		t.Fatal(err)
 	}
 }
+
+func TestLoad_HookTimeout(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `
+[hooks.post-add]
+timeout = "10m"
+
+[hooks.pre-remove]
+timeout = "30s"
+`)
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.HookTimeout("post-add"); got != 10*time.Minute {
+		t.Errorf("post-add timeout = %v, want 10m", got)
+	}
+	if got := cfg.HookTimeout("pre-remove"); got != 30*time.Second {
+		t.Errorf("pre-remove timeout = %v, want 30s", got)
+	}
+	if got := cfg.HookTimeout("pre-add"); got != 0 {
+		t.Errorf("pre-add timeout = %v, want 0", got)
+	}
+}
+
+func TestLoad_InvalidHookTimeout(t *testing.T) {
+	for _, content := range []string{
+		"[hooks.post-add]\ntimeout = \"ten minutes\"\n",
+		"[hooks.post-add]\ntimeout = \"-1s\"\n",
+		"[hooks.post-add]\ntimeout = 600\n",
+		"[hooks.post-add]\ntimeout = 1.5\n",
+	} {
+		dir := t.TempDir()
+		writeConfig(t, dir, content)
+
+		if _, err := config.Load(dir); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestGlobalDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	if got := config.GlobalDir(); got != "/xdg/config/gw" {
+		t.Errorf("got %q, want %q", got, "/xdg/config/gw")
+	}
+
+	// A relative XDG_CONFIG_HOME is invalid per the spec and ignored
+	t.Setenv("XDG_CONFIG_HOME", "relative")
+	t.Setenv("HOME", "/home/user")
+	if got := config.GlobalDir(); got != "/home/user/.config/gw" {
+		t.Errorf("got %q, want %q", got, "/home/user/.config/gw")
+	}
+}
+
+func TestLoad_GlobalConfig(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalPath := writeGlobalConfig(t, xdg, `
+worktrees_dir = "../global-trees"
+
+[hooks.post-add]
+timeout = "5m"
+`)
+	dir := t.TempDir()
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.WorktreesDir != "../global-trees" {
+		t.Errorf("got %q, want %q", cfg.WorktreesDir, "../global-trees")
+	}
+	if got := cfg.HookTimeout("post-add"); got != 5*time.Minute {
+		t.Errorf("post-add timeout = %v, want 5m", got)
+	}
+	if got := cfg.Source("worktrees_dir"); got != globalPath {
+		t.Errorf("Source(worktrees_dir) = %q, want %q", got, globalPath)
+	}
+}
+
+func TestLoad_RepoOverridesGlobal(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalPath := writeGlobalConfig(t, xdg, `
+worktrees_dir = "../global-trees"
+
+[hooks.post-add]
+timeout = "5m"
+
+[hooks.pre-remove]
+timeout = "1m"
+`)
+	dir := t.TempDir()
+	writeConfig(t, dir, `
+worktrees_dir = "../repo-trees"
+
+[hooks.post-add]
+timeout = "10m"
+`)
+	repoPath := filepath.Join(dir, ".gw", "config")
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.WorktreesDir != "../repo-trees" {
+		t.Errorf("got %q, want %q", cfg.WorktreesDir, "../repo-trees")
+	}
+	if got := cfg.HookTimeout("post-add"); got != 10*time.Minute {
+		t.Errorf("post-add timeout = %v, want 10m", got)
+	}
+	if got := cfg.HookTimeout("pre-remove"); got != time.Minute {
+		t.Errorf("pre-remove timeout = %v, want 1m", got)
+	}
+
+	sources := map[string]string{
+		"worktrees_dir":            repoPath,
+		"hooks.post-add.timeout":   repoPath,
+		"hooks.pre-remove.timeout": globalPath,
+		"hooks.pre-add.timeout":    "",
+	}
+	for key, want := range sources {
+		if got := cfg.Source(key); got != want {
+			t.Errorf("Source(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoad_PathTemplate(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalPath := writeGlobalConfig(t, xdg, `path_template = "{{.User}}/{{.Branch | sanitize}}"`)
+	dir := t.TempDir()
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{{.User}}/{{.Branch | sanitize}}"; cfg.PathTemplate != want {
+		t.Errorf("got %q, want %q", cfg.PathTemplate, want)
+	}
+	if got := cfg.Source("path_template"); got != globalPath {
+		t.Errorf("Source(path_template) = %q, want %q", got, globalPath)
+	}
+
+	writeConfig(t, dir, `path_template = "{{.Branch}}"`)
+	cfg, err = config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PathTemplate != "{{.Branch}}" {
+		t.Errorf("got %q, want %q", cfg.PathTemplate, "{{.Branch}}")
+	}
+	if got, want := cfg.Source("path_template"), filepath.Join(dir, ".gw", "config"); got != want {
+		t.Errorf("Source(path_template) = %q, want %q", got, want)
+	}
+}
+
+func TestLoad_InvalidGlobalConfig(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalPath := writeGlobalConfig(t, xdg, `this is not valid toml = [`)
+
+	_, err := config.Load(t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), globalPath) {
+		t.Errorf("expected error mentioning %s, got: %v", globalPath, err)
+	}
+}
+
+func writeGlobalConfig(t *testing.T, xdgConfigHome, content string) string {
+	t.Helper()
+	configDir := filepath.Join(xdgConfigHome, "gw")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(configDir, "config")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_TrustAllHooks_GlobalOnly(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	dir := t.TempDir()
+	writeConfig(t, dir, `trust_all_hooks = true`)
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TrustAllHooks {
+		t.Error("trust_all_hooks in .gw/config should be ignored")
+	}
+
+	globalPath := writeGlobalConfig(t, xdg, `trust_all_hooks = true`)
+	cfg, err = config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.TrustAllHooks {
+		t.Error("trust_all_hooks in the global config should be honored")
+	}
+	if got := cfg.Source("trust_all_hooks"); got != globalPath {
+		t.Errorf("Source(trust_all_hooks) = %q, want %q", got, globalPath)
+	}
+}
+
+func TestLoad_HookCommands(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalPath := writeGlobalConfig(t, xdg, `
+[hooks]
+post-add = ["echo global"]
+`)
+	dir := t.TempDir()
+	writeConfig(t, dir, `
+[hooks]
+post-add = ["npm ci", "cp $GW_REPO_ROOT/.env ."]
+
+[hooks.pre-remove]
+timeout = "30s"
+commands = ["docker compose down"]
+`)
+	repoPath := filepath.Join(dir, ".gw", "config")
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []config.HookCommand{
+		{Run: "echo global", Source: globalPath, Global: true},
+		{Run: "npm ci", Source: repoPath},
+		{Run: "cp $GW_REPO_ROOT/.env .", Source: repoPath},
+	}
+	if got := cfg.HookCommands("post-add"); !reflect.DeepEqual(got, want) {
+		t.Errorf("post-add commands = %+v, want %+v", got, want)
+	}
+	if got := cfg.HookCommands("pre-remove"); len(got) != 1 || got[0].Run != "docker compose down" {
+		t.Errorf("pre-remove commands = %+v", got)
+	}
+	if got := cfg.HookTimeout("pre-remove"); got != 30*time.Second {
+		t.Errorf("pre-remove timeout = %v, want 30s", got)
+	}
+	if got := cfg.HookCommands("pre-add"); len(got) != 0 {
+		t.Errorf("pre-add commands = %+v, want none", got)
+	}
+}
+
+func TestLoad_InvalidHookCommands(t *testing.T) {
+	for _, content := range []string{
+		"[hooks]\npost-add = [1, 2]\n",
+		"[hooks]\npost-add = \"npm ci\"\n",
+		"[hooks.post-add]\ncommands = \"npm ci\"\n",
+	} {
+		dir := t.TempDir()
+		writeConfig(t, dir, content)
+
+		if _, err := config.Load(dir); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoad_HookAsync(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "[hooks.post-add]\nasync = true\n")
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.HookAsync("post-add") {
+		t.Error("expected post-add to be async")
+	}
+	if cfg.HookAsync("pre-add") {
+		t.Error("expected pre-add not to be async")
+	}
+}
+
+func TestLoad_InvalidHookAsync(t *testing.T) {
+	for _, content := range []string{
+		"[hooks.pre-add]\nasync = true\n",
+		"[hooks.post-add]\nasync = \"yes\"\n",
+	} {
+		dir := t.TempDir()
+		writeConfig(t, dir, content)
+
+		if _, err := config.Load(dir); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoad_Sanitize(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalPath := writeGlobalConfig(t, xdg, "[sanitize]\nstrategy = \"slug\"\nmax_length = 40\n")
+	dir := t.TempDir()
+	writeConfig(t, dir, "[sanitize]\nstrategy = \"hash-suffix\"\n")
+	repoPath := filepath.Join(dir, ".gw", "config")
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pathutil.Sanitizer{Strategy: pathutil.StrategyHashSuffix, MaxLength: 40}
+	if got := cfg.Sanitizer(); got != want {
+		t.Errorf("Sanitizer() = %+v, want %+v", got, want)
+	}
+	if got := cfg.Source("sanitize.strategy"); got != repoPath {
+		t.Errorf("Source(sanitize.strategy) = %q, want %q", got, repoPath)
+	}
+	if got := cfg.Source("sanitize.max_length"); got != globalPath {
+		t.Errorf("Source(sanitize.max_length) = %q, want %q", got, globalPath)
+	}
+}
+
+func TestLoad_InvalidSanitize(t *testing.T) {
+	for _, content := range []string{
+		"[sanitize]\nstrategy = \"lowercase\"\n",
+		"[sanitize]\nmax_length = -1\n",
+		"[sanitize]\nmax_length = \"10\"\n",
+	} {
+		dir := t.TempDir()
+		writeConfig(t, dir, content)
+
+		if _, err := config.Load(dir); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoad_OnCollision(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `on_collision = "suffix"`)
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OnCollision != pathutil.CollisionSuffix {
+		t.Errorf("got %q, want %q", cfg.OnCollision, pathutil.CollisionSuffix)
+	}
+
+	writeConfig(t, dir, `on_collision = "overwrite"`)
+	if _, err := config.Load(dir); err == nil {
+		t.Error("expected error for unknown on_collision")
+	}
+}
+
+func TestLoad_Rules(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalPath := writeGlobalConfig(t, xdg, `
+[[rules]]
+branch = "release/*"
+worktrees_dir = "/global/big"
+
+[[rules]]
+branch_regex = "^(spike|exp)/"
+from = "origin/develop"
+`)
+	dir := t.TempDir()
+	writeConfig(t, dir, `
+worktrees_dir = "../trees"
+
+[[rules]]
+branch = "release/*"
+worktrees_dir = "/repo/big"
+path_template = "{{.Branch}}"
+
+[[rules]]
+branch = "hotfix/*"
+disable_hooks = ["post-add"]
+`)
+	repoPath := filepath.Join(dir, ".gw", "config")
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		branch string
+		source string
+		index  int
+	}{
+		{"release/1.0", repoPath, 0}, // the repository rule comes before the global one
+		{"hotfix/x", repoPath, 1},
+		{"spike/try", globalPath, 1},
+		{"exp/y", globalPath, 1},
+		{"release/1.0/rc", "", 0}, // "*" does not match "/"
+		{"feature/x", "", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		r := cfg.RuleFor(tt.branch)
+		if tt.source == "" {
+			if r != nil {
+				t.Errorf("RuleFor(%q) = %s, want no rule", tt.branch, r.Name())
+			}
+			continue
+		}
+		if r == nil || r.Source != tt.source || r.Index != tt.index {
+			t.Errorf("RuleFor(%q) = %+v, want rules[%d] in %s", tt.branch, r, tt.index, tt.source)
+		}
+	}
+
+	release := cfg.ForBranch("release/1.0")
+	if release.WorktreesDir != "/repo/big" || release.PathTemplate != "{{.Branch}}" {
+		t.Errorf("release settings = %q, %q", release.WorktreesDir, release.PathTemplate)
+	}
+	if release.Rule() == nil || release.Source("worktrees_dir") != repoPath {
+		t.Errorf("release rule = %v, Source(worktrees_dir) = %q", release.Rule(), release.Source("worktrees_dir"))
+	}
+	if cfg.WorktreesDir != "../trees" {
+		t.Errorf("ForBranch modified the base config: worktrees_dir = %q", cfg.WorktreesDir)
+	}
+
+	hotfix := cfg.ForBranch("hotfix/x")
+	if hotfix.WorktreesDir != "../trees" {
+		t.Errorf("hotfix worktrees_dir = %q, want the base value", hotfix.WorktreesDir)
+	}
+	if hotfix.HookEnabled("post-add") || !hotfix.HookEnabled("pre-add") {
+		t.Error("expected only post-add to be disabled for hotfix/x")
+	}
+	if got := cfg.ForBranch("spike/try").StartRef(); got != "origin/develop" {
+		t.Errorf("spike start ref = %q, want origin/develop", got)
+	}
+	if got := cfg.ForBranch("feature/x"); got.Rule() != nil || got.StartRef() != "" || !got.HookEnabled("post-add") {
+		t.Error("expected default settings for feature/x")
+	}
+}
+
+func TestLoad_InvalidRules(t *testing.T) {
+	for _, content := range []string{
+		"[[rules]]\nworktrees_dir = \"/big\"\n",
+		"[[rules]]\nbranch = \"a/*\"\nbranch_regex = \"^a/\"\n",
+		"[[rules]]\nbranch = \"[a\"\n",
+		"[[rules]]\nbranch_regex = \"(a\"\n",
+		"[[rules]]\nbranch = \"a\"\ndisable_hooks = \"post-add\"\n",
+	} {
+		dir := t.TempDir()
+		writeConfig(t, dir, content)
+
+		if _, err := config.Load(dir); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestSetKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gw", "config")
+
+	// Creates the file and its directory.
+	if err := config.SetKey(path, "on_collision", "suffix", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`# Settings shared by the team
+worktrees_dir = "../trees"  # next to the repository
+
+[sanitize]
+strategy = "slug"
+
+# Hooks
+[hooks.post-add]
+async = true
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, kv := range [][2]string{
+		{"worktrees_dir", "../wt"},
+		{"sanitize.max_length", "40"},
+		{"hooks.post-add.timeout", "5m"},
+		{"hooks.pre-remove.async", "false"},
+		{"path_template", "{{.Branch}}"},
+	} {
+		if err := config.SetKey(path, kv[0], kv[1], false); err != nil {
+			t.Fatalf("SetKey(%s): %v", kv[0], err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Settings shared by the team
+worktrees_dir = "../wt"  # next to the repository
+path_template = "{{.Branch}}"
+
+[sanitize]
+strategy = "slug"
+max_length = 40
+
+# Hooks
+[hooks.post-add]
+async = true
+timeout = "5m"
+
+[hooks.pre-remove]
+async = false
+`
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the file mode to be kept, got %v (err = %v)", info.Mode(), err)
+	}
+}
+
+func TestSetKey_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	original := "worktrees_dir = \"../trees\"\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		key, value string
+		global     bool
+	}{
+		{"worktree_dir", "x", false},
+		{"rules.branch", "x", false},
+		{"on_collision", "bogus", false},
+		{"sanitize.max_length", "-1", false},
+		{"sanitize.max_length", "many", false},
+		{"hooks.post-add.timeout", "soon", false},
+		{"hooks.post-add.async", "maybe", false},
+		{"hooks.post-add.commands", "make", false},
+		{"trust_all_hooks", "true", false},
+	} {
+		if err := config.SetKey(path, tc.key, tc.value, tc.global); err == nil {
+			t.Errorf("SetKey(%s, %s): expected error", tc.key, tc.value)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != original {
+		t.Errorf("expected the file to be unchanged, got:\n%s", data)
+	}
+}
+
+func TestUnsetKey(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `# comment
+worktrees_dir = "../trees"
+on_collision = "suffix"
+
+[hooks.post-add]
+timeout = "5m"
+`)
+	path := filepath.Join(dir, ".gw", "config")
+
+	if err := config.UnsetKey(path, "on_collision", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.UnsetKey(path, "hooks.post-add.timeout", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.UnsetKey(path, "path_template", false); err == nil {
+		t.Error("expected error for a key that is not set")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# comment
+worktrees_dir = "../trees"
+
+[hooks.post-add]
+`
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalPath := writeGlobalConfig(t, xdg, `
+trust_all_hooks = true
+worktrees_dir = "/global"
+`)
+	dir := t.TempDir()
+	writeConfig(t, dir, `
+worktrees_dir = "../trees"
+
+[hooks.post-add]
+commands = ["make", "make test"]
+timeout = "1m"
+
+[[rules]]
+branch = "hotfix/*"
+`)
+	repoPath := filepath.Join(dir, ".gw", "config")
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []config.Setting{
+		{Key: "worktrees_dir", Value: "../trees", Source: repoPath},
+		{Key: "trust_all_hooks", Value: "true", Source: globalPath},
+		{Key: "hooks.post-add.timeout", Value: "1m0s", Source: repoPath},
+		{Key: "hooks.post-add.commands", Value: "make", Source: repoPath},
+		{Key: "hooks.post-add.commands", Value: "make test", Source: repoPath},
+		{Key: "rules[0]", Value: `branch = "hotfix/*"`, Source: repoPath},
+	}
+	if got := cfg.Settings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Settings() = %v, want %v", got, want)
+	}
+
+	if got, err := cfg.Get("hooks.post-add.commands"); err != nil || !reflect.DeepEqual(got, []string{"make", "make test"}) {
+		t.Errorf("Get(commands) = %v, %v", got, err)
+	}
+	if got, err := cfg.Get("path_template"); err != nil || len(got) != 0 {
+		t.Errorf("Get(path_template) = %v, %v; want no values", got, err)
+	}
+	if _, err := cfg.Get("worktree_dir"); err == nil {
+		t.Error("expected error for an unknown key")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalPath := writeGlobalConfig(t, xdg, `
+trust_all_hooks = true
+[hooks.post-add]
+timout = "1m"
+`)
+	dir := t.TempDir()
+	writeConfig(t, dir, `
+path_template = "../../{{.Branch}}"
+trust_all_hooks = true
+
+[sanitize]
+strategy = "slug"
+max_lenght = 10
+
+[hooks.post-ad]
+timeout = "1m"
+
+[[rules]]
+branch = "tmp/*"
+worktrees_dir = ".gw/tmp"
+disable_hooks = ["post-add", "pre-ad"]
+`)
+	repoPath := filepath.Join(dir, ".gw", "config")
+
+	problems, err := config.Check(dir, []string{"pre-add", "post-add"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	want := []string{
+		globalPath + `: hooks.post-add.timout: unknown key (did you mean "timeout"?)`,
+		repoPath + `: sanitize.max_lenght: unknown key (did you mean "max_length"?)`,
+		repoPath + `: hooks.post-ad: unknown hook (did you mean "post-add"?)`,
+		repoPath + ": trust_all_hooks: only honored in the global config file; ignored here",
+		repoPath + `: path_template: path_template "../../{{.Branch}}" produced "../../feature/example" for branch "feature/example", which is not a path inside ` + dir + "-worktrees",
+		repoPath + `: rules[0].disable_hooks: unknown hook "pre-ad"`,
+		repoPath + ": rules[0].worktrees_dir: resolves to " + filepath.Join(dir, ".gw", "tmp") + ", inside the repository's .gw directory",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Type errors are reported per key.
+	writeConfig(t, dir, "worktrees_dir = 1\n[hooks.post-add]\ntimeout = 600\nasync = \"yes\"\n")
+	writeGlobalConfig(t, xdg, "")
+	problems, err = config.Check(dir, []string{"post-add"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	want = []string{
+		repoPath + ": worktrees_dir: expected a string, got integer",
+		repoPath + `: hooks.post-add.timeout: expected a duration string such as "10m", got integer 600`,
+		repoPath + ": hooks.post-add.async: expected true or false, got string",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Syntax errors are reported for the whole file.
+	writeConfig(t, dir, "worktrees_dir = \n")
+	problems, err = config.Check(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].File != repoPath || problems[0].Key != "" {
+		t.Errorf("Check() = %v, want one problem with %s", problems, repoPath)
+	}
+
+	// Load ignores what Check reports as unknown.
+	writeConfig(t, dir, "worktree_dir = \"../wt\"\n")
+	if _, err := config.Load(dir); err != nil {
+		t.Errorf("Load: %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The config file is edited line by line rather than re-encoded, so that comments and layout survive.
+var (
+	tableHeaderRe = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+	arrayHeaderRe = regexp.MustCompile(`^\s*\[\[`)
+	keyLineRe     = regexp.MustCompile(`^(\s*)((?:[A-Za-z0-9_-]+|"[^"]*"|'[^']*')(?:\s*\.\s*(?:[A-Za-z0-9_-]+|"[^"]*"|'[^']*'))*)\s*=\s*(.*)$`)
+	// scalarValueRe splits a single-line scalar value from a trailing comment.
+	scalarValueRe = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|'[^']*'|[^\s#"']+)(\s*#.*)?$`)
+)
+
+// SetKey sets key to value in the config file at path, creating the file if needed.
+// value is given as on the command line and converted to the type of key.
+// An existing assignment is replaced in place; otherwise the key is added to its table.
+// The file is left unchanged if the result would not load.
+func SetKey(path, key, value string, global bool) error {
+	if key == "trust_all_hooks" && !global {
+		return fmt.Errorf("trust_all_hooks is only honored in the global config file")
+	}
+	encoded, err := encodeValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	lines := splitConfigLines(string(data))
+
+	table, name := splitKey(key)
+	if i, ok := findKeyLine(lines, key); ok {
+		m := keyLineRe.FindStringSubmatch(lines[i])
+		comment := ""
+		if v := scalarValueRe.FindStringSubmatch(m[3]); v != nil {
+			comment = v[2]
+		} else if strings.HasPrefix(m[3], `"""`) || strings.HasPrefix(m[3], `'''`) {
+			return fmt.Errorf("cannot edit %s in %s: multi-line values are not supported; edit the file instead", key, path)
+		}
+		lines[i] = m[1] + m[2] + " = " + encoded + comment
+	} else {
+		lines = insertKey(lines, table, name+" = "+encoded)
+	}
+
+	return writeConfigLines(path, lines, global, key, true)
+}
+
+// UnsetKey removes the assignment of key from the config file at path.
+func UnsetKey(path, key string, global bool) error {
+	if _, err := keyKind(key); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%s is not set in %s", key, path)
+		}
+		return err
+	}
+	lines := splitConfigLines(string(data))
+
+	i, ok := findKeyLine(lines, key)
+	if !ok {
+		return fmt.Errorf("%s is not set in %s", key, path)
+	}
+	m := keyLineRe.FindStringSubmatch(lines[i])
+	if scalarValueRe.FindStringSubmatch(m[3]) == nil {
+		return fmt.Errorf("cannot remove %s from %s: it spans several lines; edit the file instead", key, path)
+	}
+	lines = append(lines[:i], lines[i+1:]...)
+
+	return writeConfigLines(path, lines, global, key, false)
+}
+
+// writeConfigLines checks that the edited file loads and defines key as expected, then writes it.
+func writeConfigLines(path string, lines []string, global bool, key string, defined bool) error {
+	content := strings.Join(lines, "\n") + "\n"
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*")
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if tmp, err = os.CreateTemp(filepath.Dir(path), ".config-*"); err != nil {
+			return err
+		}
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	cfg := &Config{sources: map[string]string{}}
+	if err := cfg.merge(tmp.Name(), global); err != nil {
+		return errors.New(strings.ReplaceAll(err.Error(), tmp.Name(), path))
+	}
+	if (cfg.Source(key) != "") != defined {
+		return fmt.Errorf("cannot edit %s in %s: it is written in a form gw config cannot change; edit the file instead", key, path)
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func splitConfigLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// splitKey splits a dotted key into its table and the name within it: "hooks.post-add.timeout"
+// becomes "hooks.post-add" and "timeout"; top-level keys have an empty table.
+func splitKey(key string) (table, name string) {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+// normalizeKey removes quotes and whitespace from a TOML key as written, e.g. `hooks . "post-add"`.
+func normalizeKey(key string) string {
+	var parts []string
+	for _, p := range strings.Split(key, ".") {
+		parts = append(parts, strings.Trim(strings.TrimSpace(p), `"'`))
+	}
+	return strings.Join(parts, ".")
+}
+
+// findKeyLine returns the line that assigns key, either in its own table or as a dotted key in a parent table.
+func findKeyLine(lines []string, key string) (int, bool) {
+	table := ""
+	inArray := false
+	for i, line := range lines {
+		if arrayHeaderRe.MatchString(line) {
+			inArray = true
+			continue
+		}
+		if m := tableHeaderRe.FindStringSubmatch(line); m != nil {
+			table, inArray = normalizeKey(m[1]), false
+			continue
+		}
+		if inArray {
+			continue
+		}
+		m := keyLineRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		full := normalizeKey(m[2])
+		if table != "" {
+			full = table + "." + full
+		}
+		if full == key {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// insertKey adds line to table: after the last assignment of the table if it exists,
+// before the first table for top-level keys, or in a new table at the end of the file.
+func insertKey(lines []string, table, line string) []string {
+	headerAt := -1 // line of the table header; -1 for the top level
+	end := len(lines)
+	if table != "" {
+		end = -1
+		for i, l := range lines {
+			if m := tableHeaderRe.FindStringSubmatch(l); m != nil && normalizeKey(m[1]) == table {
+				headerAt = i
+				break
+			}
+		}
+		if headerAt < 0 {
+			if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+				lines = append(lines, "")
+			}
+			return append(lines, "["+table+"]", line)
+		}
+	}
+
+	// The table ends at the next header.
+	for i := headerAt + 1; i < len(lines); i++ {
+		if tableHeaderRe.MatchString(lines[i]) || arrayHeaderRe.MatchString(lines[i]) {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		end = len(lines)
+	}
+
+	// Insert after the last non-blank, non-comment line of the table, so that
+	// comments introducing the next table stay with it.
+	at := headerAt + 1
+	for i := end - 1; i > headerAt; i-- {
+		if t := strings.TrimSpace(lines[i]); t != "" && !strings.HasPrefix(t, "#") {
+			at = i + 1
+			break
+		}
+	}
+	if headerAt < 0 && at == 0 && end < len(lines) {
+		// A file that starts with tables: keep the new key apart from them.
+		return append([]string{line, ""}, lines...)
+	}
+	return append(lines[:at], append([]string{line}, lines[at:]...)...)
+}
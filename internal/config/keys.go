@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// kind is the type of value a settable key holds.
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt
+	kindDuration // written as a string such as "10m"
+	kindList     // array of strings; listed but not settable with SetKey
+)
+
+// scalarKeys are the keys outside [hooks] and [[rules]], in the order Settings lists them.
+var scalarKeys = []struct {
+	key  string
+	kind kind
+}{
+	{"worktrees_dir", kindString},
+	{"path_template", kindString},
+	{"sanitize.strategy", kindString},
+	{"sanitize.max_length", kindInt},
+	{"on_collision", kindString},
+	{"trust_all_hooks", kindBool},
+}
+
+// hookKeys are the settings of a hook phase, [hooks.<name>].
+var hookKeys = []struct {
+	key  string
+	kind kind
+}{
+	{"timeout", kindDuration},
+	{"async", kindBool},
+	{"commands", kindList},
+}
+
+// ruleKeys are the keys of a [[rules]] table.
+var ruleKeys = []string{"branch", "branch_regex", "worktrees_dir", "path_template", "from", "disable_hooks"}
+
+// keyKind returns the kind of value key holds, or an error if key is unknown or cannot be addressed
+// by name (e.g. the tables of [[rules]]).
+func keyKind(key string) (kind, error) {
+	for _, k := range scalarKeys {
+		if k.key == key {
+			return k.kind, nil
+		}
+	}
+	if parts := strings.Split(key, "."); len(parts) == 3 && parts[0] == "hooks" && parts[1] != "" {
+		for _, k := range hookKeys {
+			if k.key == parts[2] {
+				return k.kind, nil
+			}
+		}
+	}
+	if key == "rules" || strings.HasPrefix(key, "rules.") || strings.HasPrefix(key, "rules[") {
+		return 0, fmt.Errorf("rules cannot be addressed by key; edit the config file instead")
+	}
+	return 0, fmt.Errorf("unknown config key %q", key)
+}
+
+// Setting is a key set in a config file with its value in effect.
+type Setting struct {
+	Key    string // e.g. "hooks.post-add.timeout", or "rules[0]" for a rule
+	Value  string // strings unquoted; a rule is shown by its pattern
+	Source string // config file that set the key
+}
+
+// Settings returns the keys set in the loaded config files with their values in effect, in a stable order.
+// Each inline hook command and each rule is a separate entry.
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	for _, k := range scalarKeys {
+		if src := c.Source(k.key); src != "" {
+			settings = append(settings, Setting{k.key, c.scalarValue(k.key), src})
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Hooks)) {
+		h := c.Hooks[name]
+		prefix := "hooks." + name + "."
+		if src := c.Source(prefix + "timeout"); src != "" {
+			settings = append(settings, Setting{prefix + "timeout", h.Timeout.String(), src})
+		}
+		if src := c.Source(prefix + "async"); src != "" {
+			settings = append(settings, Setting{prefix + "async", strconv.FormatBool(h.Async), src})
+		}
+		for _, cmd := range h.Commands {
+			settings = append(settings, Setting{prefix + "commands", cmd.Run, cmd.Source})
+		}
+	}
+
+	for _, r := range c.Rules {
+		settings = append(settings, Setting{fmt.Sprintf("rules[%d]", r.Index), r.Pattern(), r.Source})
+	}
+	return settings
+}
+
+func (c *Config) scalarValue(key string) string {
+	switch key {
+	case "worktrees_dir":
+		return c.WorktreesDir
+	case "path_template":
+		return c.PathTemplate
+	case "sanitize.strategy":
+		return c.Sanitize.Strategy
+	case "sanitize.max_length":
+		return strconv.Itoa(c.Sanitize.MaxLength)
+	case "on_collision":
+		return c.OnCollision
+	case "trust_all_hooks":
+		return strconv.FormatBool(c.TrustAllHooks)
+	}
+	return ""
+}
+
+// Get returns the values in effect for key: one value for scalar keys, one per command for
+// hooks.<name>.commands. It returns no values if key is not set in any config file.
+func (c *Config) Get(key string) ([]string, error) {
+	if _, err := keyKind(key); err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, s := range c.Settings() {
+		if s.Key == key {
+			values = append(values, s.Value)
+		}
+	}
+	return values, nil
+}
+
+// encodeValue converts the command-line value of key to its TOML representation.
+func encodeValue(key, value string) (string, error) {
+	k, err := keyKind(key)
+	if err != nil {
+		return "", err
+	}
+
+	var v any
+	switch k {
+	case kindString:
+		v = value
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid value for %s: expected true or false", key)
+		}
+		v = b
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid value for %s: expected an integer", key)
+		}
+		v = n
+	case kindDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return "", fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		v = value
+	case kindList:
+		return "", fmt.Errorf("%s is a list; edit the config file instead", key)
+	}
+
+	var sb strings.Builder
+	if err := toml.NewEncoder(&sb).Encode(map[string]any{"v": v}); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimPrefix(sb.String(), "v = ")), nil
+}
@@ -5,12 +5,23 @@ import (
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // RepoRoot returns the root directory of the main repository.
 // When called from a worktree, it returns the main repository root, not the worktree root.
 func RepoRoot(dir string) (string, error) {
+	gitCommonDir, err := CommonDir(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(gitCommonDir), nil
+}
+
+// CommonDir returns the absolute path of the git directory shared by all worktrees (usually <repo>/.git).
+func CommonDir(dir string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--git-common-dir")
 	cmd.Dir = dir
 	out, err := cmd.Output()
@@ -27,12 +38,7 @@ func RepoRoot(dir string) (string, error) {
 	gitCommonDir = filepath.Clean(gitCommonDir)
 
 	// Resolve symlinks for consistent path representation
-	gitCommonDir, err = filepath.EvalSymlinks(gitCommonDir)
-	if err != nil {
-		return "", err
-	}
-
-	return filepath.Dir(gitCommonDir), nil
+	return filepath.EvalSymlinks(gitCommonDir)
 }
 
 // DefaultBranch returns the default branch name from origin/HEAD.
@@ -53,6 +59,25 @@ func DefaultBranch(repoRoot string) (string, error) {
 	return strings.TrimPrefix(ref, prefix), nil
 }
 
+// DefaultRef returns the ref that new branches start from and that merges are checked against:
+// "origin/<default>" when the remote-tracking ref exists, otherwise the local default branch.
+func DefaultRef(repoRoot string) (string, error) {
+	defaultBranch, err := DefaultBranch(repoRoot)
+	if err != nil {
+		return "", err
+	}
+
+	remoteRef := "origin/" + defaultBranch
+	remoteExists, err := RemoteRefExists(repoRoot, remoteRef)
+	if err != nil {
+		return "", err
+	}
+	if remoteExists {
+		return remoteRef, nil
+	}
+	return defaultBranch, nil
+}
+
 // BranchExists checks if a local branch exists.
 func BranchExists(repoRoot, branch string) (bool, error) {
 	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
@@ -83,16 +108,158 @@ func RemoteRefExists(repoRoot, ref string) (bool, error) {
 	return false, err
 }
 
+// IsAncestor reports whether commit is an ancestor of target, i.e. whether it has been merged into target.
+func IsAncestor(repoRoot, commit, target string) (bool, error) {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", commit, target)
+	cmd.Dir = repoRoot
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check whether %s is merged into %s: %w", commit, target, err)
+}
+
+// IsMerged reports whether branch, whose tip is commit, was merged into target with commits of its own:
+// commit must be an ancestor of target and differ from the commit the branch was created at,
+// the oldest entry of its reflog. This covers fast-forward merges as well as merge commits.
+// A branch still at its creation point, a detached HEAD (empty branch) or a branch without
+// a reflog only counts as merged if commit is not on target's first-parent history,
+// so that a branch created from target without new commits is not merged.
+// The branch of target itself (main for target main or origin/main) is never merged.
+func IsMerged(repoRoot, branch, commit, target string) (bool, error) {
+	if branch != "" && (branch == target || "origin/"+branch == target) {
+		return false, nil
+	}
+	ancestor, err := IsAncestor(repoRoot, commit, target)
+	if err != nil || !ancestor {
+		return false, err
+	}
+	sha, err := ResolveCommit(repoRoot, commit)
+	if err != nil {
+		return false, err
+	}
+
+	if branch != "" {
+		cmd := exec.Command("git", "reflog", "show", "--format=%H", "refs/heads/"+branch, "--")
+		cmd.Dir = repoRoot
+		out, err := cmd.Output()
+		if err != nil {
+			return false, fmt.Errorf("failed to read the reflog of %s: %w", branch, err)
+		}
+		if entries := strings.Fields(string(out)); len(entries) > 0 && entries[len(entries)-1] != sha {
+			return true, nil
+		}
+	}
+
+	// Walk target's first parents down to the first commit reachable from commit.
+	// commit is on that history iff the last commit walked has commit as its first parent.
+	cmd := exec.Command("git", "rev-list", "--first-parent", "--parents", target, "^"+sha)
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether %s is merged into %s: %w", commit, target, err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	last := strings.Fields(lines[len(lines)-1])
+	if len(last) < 2 {
+		// Nothing walked: commit is target itself.
+		return false, nil
+	}
+	return last[1] != sha, nil
+}
+
+// ResolveCommit returns the full SHA of the commit rev points to.
+func ResolveCommit(dir, rev string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s to a commit: %w", rev, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CommitTime returns the committer date of rev.
+func CommitTime(repoRoot, rev string) (time.Time, error) {
+	cmd := exec.Command("git", "show", "-s", "--format=%ct", rev)
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get commit time of %s: %w", rev, err)
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected commit time for %s: %w", rev, err)
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// IsDirty reports whether the worktree at dir has uncommitted changes or untracked files.
+func IsDirty(dir string) (bool, error) {
+	cmd := exec.Command("git", "status", "--porcelain")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to get status of %s: %w", dir, err)
+	}
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
+// Upstream describes the upstream tracking state of a local branch.
+// Ref is empty when the branch has no upstream configured.
+type Upstream struct {
+	Ref    string
+	Gone   bool
+	Ahead  int
+	Behind int
+}
+
+// BranchUpstream returns the upstream tracking state of a local branch.
+func BranchUpstream(repoRoot, branch string) (Upstream, error) {
+	cmd := exec.Command("git", "for-each-ref", "--format=%(upstream:short)%00%(upstream:track,nobracket)", "refs/heads/"+branch)
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return Upstream{}, fmt.Errorf("failed to get upstream of %s: %w", branch, err)
+	}
+
+	ref, track, _ := strings.Cut(strings.TrimSpace(string(out)), "\x00")
+	up := Upstream{Ref: ref}
+	for _, part := range strings.Split(track, ", ") {
+		switch {
+		case part == "gone":
+			up.Gone = true
+		case strings.HasPrefix(part, "ahead "):
+			fmt.Sscanf(part, "ahead %d", &up.Ahead)
+		case strings.HasPrefix(part, "behind "):
+			fmt.Sscanf(part, "behind %d", &up.Behind)
+		}
+	}
+	return up, nil
+}
+
 // RepoName returns the repository name (basename of the repo root).
 func RepoName(repoRoot string) string {
 	return filepath.Base(repoRoot)
 }
 
-// Worktree represents a git worktree entry.
-// Branch is empty for detached HEAD worktrees.
+// Worktree represents a git worktree entry as reported by `git worktree list --porcelain`.
+// Branch is empty for detached HEAD and bare worktrees.
 type Worktree struct {
-	Path   string
-	Branch string
+	Path           string `json:"path"`
+	Branch         string `json:"branch"`
+	Head           string `json:"head"`
+	Main           bool   `json:"main"`
+	Detached       bool   `json:"detached"`
+	Bare           bool   `json:"bare"`
+	Locked         bool   `json:"locked"`
+	LockedReason   string `json:"locked_reason,omitempty"`
+	Prunable       bool   `json:"prunable"`
+	PrunableReason string `json:"prunable_reason,omitempty"`
 }
 
 // ListLocalBranches returns the short names of all local branches.
@@ -126,6 +293,7 @@ func splitLines(s string) []string {
 }
 
 // ListWorktrees parses `git worktree list --porcelain` and returns all worktrees.
+// The first entry is the main worktree.
 func ListWorktrees(repoRoot string) ([]Worktree, error) {
 	cmd := exec.Command("git", "worktree", "list", "--porcelain")
 	cmd.Dir = repoRoot
@@ -138,14 +306,27 @@ func ListWorktrees(repoRoot string) ([]Worktree, error) {
 	var current Worktree
 
 	for _, line := range strings.Split(string(out), "\n") {
-		switch {
-		case strings.HasPrefix(line, "worktree "):
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "worktree":
 			if current.Path != "" {
 				worktrees = append(worktrees, current)
 			}
-			current = Worktree{Path: strings.TrimPrefix(line, "worktree ")}
-		case strings.HasPrefix(line, "branch refs/heads/"):
-			current.Branch = strings.TrimPrefix(line, "branch refs/heads/")
+			current = Worktree{Path: value}
+		case "HEAD":
+			current.Head = value
+		case "branch":
+			current.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "detached":
+			current.Detached = true
+		case "bare":
+			current.Bare = true
+		case "locked":
+			current.Locked = true
+			current.LockedReason = value
+		case "prunable":
+			current.Prunable = true
+			current.PrunableReason = value
 		}
 	}
 
@@ -153,5 +334,9 @@ func ListWorktrees(repoRoot string) ([]Worktree, error) {
 		worktrees = append(worktrees, current)
 	}
 
+	if len(worktrees) > 0 {
+		worktrees[0].Main = true
+	}
+
 	return worktrees, nil
 }
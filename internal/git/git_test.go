@@ -1,7 +1,10 @@
 package git_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/gin0606/gw/internal/git"
 	"github.com/gin0606/gw/internal/testutil"
@@ -62,6 +65,31 @@ func TestDefaultBranch_NotSet(t *testing.T) {
 	}
 }
 
+func TestDefaultRef_Remote(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	ref, err := git.DefaultRef(repo.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "origin/main" {
+		t.Errorf("got %q, want %q", ref, "origin/main")
+	}
+}
+
+func TestDefaultRef_LocalFallback(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.DeleteRemoteRef("origin/main")
+
+	ref, err := git.DefaultRef(repo.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "main" {
+		t.Errorf("got %q, want %q", ref, "main")
+	}
+}
+
 func TestBranchExists_Exists(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 	repo.CreateBranch("feature-test")
@@ -227,3 +255,329 @@ func TestListWorktrees_WithWorktrees(t *testing.T) {
 		t.Errorf("worktree with path %q and branch %q not found in %v", wtPath, "feature/test", worktrees)
 	}
 }
+
+func TestListWorktrees_MainFlag(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateWorktreeInBaseDir("feature/main-flag")
+
+	worktrees, err := git.ListWorktrees(repo.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(worktrees) != 2 {
+		t.Fatalf("got %d worktrees, want 2", len(worktrees))
+	}
+	if !worktrees[0].Main {
+		t.Error("expected first worktree to be the main worktree")
+	}
+	if worktrees[1].Main {
+		t.Error("expected second worktree not to be the main worktree")
+	}
+}
+
+func TestListWorktrees_Head(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	want := repo.HeadSHA("HEAD")
+
+	worktrees, err := git.ListWorktrees(repo.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if worktrees[0].Head != want {
+		t.Errorf("got HEAD %q, want %q", worktrees[0].Head, want)
+	}
+}
+
+func TestListWorktrees_Detached(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateDetachedWorktree("detached-wt")
+
+	worktrees, err := git.ListWorktrees(repo.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, wt := range worktrees {
+		if wt.Path != wtPath {
+			continue
+		}
+		if !wt.Detached {
+			t.Error("expected worktree to be detached")
+		}
+		if wt.Branch != "" {
+			t.Errorf("expected empty branch for detached worktree, got %q", wt.Branch)
+		}
+		return
+	}
+	t.Errorf("worktree %q not found in %v", wtPath, worktrees)
+}
+
+func TestListWorktrees_Locked(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("locked-wt", "locked-branch")
+	repo.LockWorktree(wtPath, "on external disk")
+
+	worktrees, err := git.ListWorktrees(repo.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, wt := range worktrees {
+		if wt.Path != wtPath {
+			continue
+		}
+		if !wt.Locked {
+			t.Error("expected worktree to be locked")
+		}
+		if wt.LockedReason != "on external disk" {
+			t.Errorf("got locked reason %q, want %q", wt.LockedReason, "on external disk")
+		}
+		return
+	}
+	t.Errorf("worktree %q not found in %v", wtPath, worktrees)
+}
+
+func TestListWorktrees_Prunable(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("prunable-wt", "prunable-branch")
+	if err := os.RemoveAll(wtPath); err != nil {
+		t.Fatal(err)
+	}
+
+	worktrees, err := git.ListWorktrees(repo.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, wt := range worktrees {
+		if wt.Path != wtPath {
+			continue
+		}
+		if !wt.Prunable {
+			t.Error("expected worktree to be prunable")
+		}
+		if wt.PrunableReason == "" {
+			t.Error("expected prunable reason to be set")
+		}
+		return
+	}
+	t.Errorf("worktree %q not found in %v", wtPath, worktrees)
+}
+
+func TestIsAncestor(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("ancestor-wt", "ancestor-branch")
+
+	merged, err := git.IsAncestor(repo.Root, "ancestor-branch", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged {
+		t.Error("expected branch without new commits to be merged")
+	}
+
+	repo.CommitFile(wtPath, "new.txt", "new")
+
+	merged, err = git.IsAncestor(repo.Root, "ancestor-branch", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged {
+		t.Error("expected branch with new commits not to be merged")
+	}
+}
+
+func TestIsAncestor_UnknownRef(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, err := git.IsAncestor(repo.Root, "nonexistent", "main")
+	if err == nil {
+		t.Error("expected error for unknown ref")
+	}
+}
+
+func TestIsMerged(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("merged-wt", "merged-branch")
+
+	isMerged := func(branch string) bool {
+		t.Helper()
+		merged, err := git.IsMerged(repo.Root, branch, branch, "main")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return merged
+	}
+
+	for _, branch := range []string{"main", "merged-branch"} {
+		if isMerged(branch) {
+			t.Errorf("expected %s without commits of its own not to be merged", branch)
+		}
+	}
+
+	repo.CommitFile(wtPath, "new.txt", "new")
+	if isMerged("merged-branch") {
+		t.Error("expected unmerged branch not to be merged")
+	}
+
+	repo.MergeBranchNoFF("merged-branch")
+	repo.CommitFile(repo.Root, "later.txt", "later")
+	if !isMerged("merged-branch") {
+		t.Error("expected branch merged with a merge commit to be merged")
+	}
+	if isMerged("main") {
+		t.Error("expected the target's own branch not to be merged")
+	}
+
+	// A branch created from main after the merge has no commits of its own.
+	repo.CreateBranch("fresh-branch")
+	if isMerged("fresh-branch") {
+		t.Error("expected fresh branch not to be merged")
+	}
+
+	// Without a branch, commits on main's first-parent history are not merged.
+	merged, err := git.IsMerged(repo.Root, "", "merged-branch", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged {
+		t.Error("expected merged commit of a detached HEAD to be merged")
+	}
+	merged, err = git.IsMerged(repo.Root, "", "main~1", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged {
+		t.Error("expected commit on main's first-parent history of a detached HEAD not to be merged")
+	}
+}
+
+func TestIsMerged_FastForward(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("ff-wt", "ff-branch")
+	repo.CommitFile(wtPath, "new.txt", "new")
+	repo.MergeBranch("ff-branch")
+
+	merged, err := git.IsMerged(repo.Root, "ff-branch", "ff-branch", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged {
+		t.Error("expected fast-forwarded branch to be merged")
+	}
+}
+
+func TestIsDirty(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("dirty-wt", "dirty-branch")
+
+	dirty, err := git.IsDirty(wtPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirty {
+		t.Error("expected fresh worktree to be clean")
+	}
+
+	if err := os.WriteFile(filepath.Join(wtPath, "untracked.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dirty, err = git.IsDirty(wtPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dirty {
+		t.Error("expected worktree with untracked file to be dirty")
+	}
+}
+
+func TestBranchUpstream_None(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateBranch("no-upstream")
+
+	up, err := git.BranchUpstream(repo.Root, "no-upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if up != (git.Upstream{}) {
+		t.Errorf("expected zero upstream, got %+v", up)
+	}
+}
+
+func TestBranchUpstream_AheadBehind(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	wtPath := repo.CreateWorktree("upstream-wt", "upstream-branch")
+	repo.SetUpstream("upstream-branch", "origin/main")
+	repo.CommitFile(wtPath, "a.txt", "a")
+	repo.CommitFile(wtPath, "b.txt", "b")
+	repo.CommitFile(repo.Root, "c.txt", "c")
+	repo.PushBranch("main")
+
+	up, err := git.BranchUpstream(repo.Root, "upstream-branch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := git.Upstream{Ref: "origin/main", Ahead: 2, Behind: 1}
+	if up != want {
+		t.Errorf("got %+v, want %+v", up, want)
+	}
+}
+
+func TestBranchUpstream_Gone(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateBranch("gone-branch")
+	repo.PushBranch("gone-branch")
+	repo.SetUpstream("gone-branch", "origin/gone-branch")
+	repo.DeleteRemoteRef("origin/gone-branch")
+
+	up, err := git.BranchUpstream(repo.Root, "gone-branch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !up.Gone {
+		t.Errorf("expected upstream to be gone, got %+v", up)
+	}
+}
+
+func TestResolveCommit(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateTag("v1.0.0")
+
+	for _, rev := range []string{"HEAD", "main", "origin/main", "v1.0.0"} {
+		got, err := git.ResolveCommit(repo.Root, rev)
+		if err != nil {
+			t.Fatalf("%s: %v", rev, err)
+		}
+		if want := repo.HeadSHA("main"); got != want {
+			t.Errorf("%s: got %q, want %q", rev, got, want)
+		}
+	}
+
+	if _, err := git.ResolveCommit(repo.Root, "nonexistent"); err == nil {
+		t.Error("expected error for unknown rev")
+	}
+}
+
+func TestCommitTime(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	committed, err := git.CommitTime(repo.Root, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if age := time.Since(committed); age < 0 || age > time.Hour {
+		t.Errorf("unexpected commit time %v (age %v)", committed, age)
+	}
+}
+
+func TestCommitTime_UnknownRev(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	_, err := git.CommitTime(repo.Root, "nonexistent")
+	if err == nil {
+		t.Error("expected error for unknown rev")
+	}
+}
@@ -0,0 +1,40 @@
+package hook
+
+// EventVersion is the version of the Event schema. It is incremented on incompatible changes.
+const EventVersion = 1
+
+// Event is the JSON document written to the stdin of every hook script.
+// It carries the same information as the GW_* environment variables in structured form.
+type Event struct {
+	Version   int               `json:"version"`
+	Hook      string            `json:"hook"`
+	GwVersion string            `json:"gw_version"`
+	Repo      EventRepo         `json:"repo"`
+	Worktree  EventWorktree     `json:"worktree"`
+	Branch    string            `json:"branch"`
+	BaseRef   string            `json:"base_ref,omitempty"` // pre-add/post-add of a new branch
+	Flags     map[string]bool   `json:"flags"`              // e.g. "branch_created", "force"
+	Move      *EventMove        `json:"move,omitempty"`     // pre-move/post-move
+	Outputs   map[string]string `json:"outputs"`            // outputs declared by earlier hooks of the worktree
+}
+
+// EventRepo describes the main repository.
+type EventRepo struct {
+	Root string `json:"root"`
+	Name string `json:"name"`
+}
+
+// EventWorktree describes the worktree the hook runs for.
+type EventWorktree struct {
+	Path          string `json:"path"`
+	SanitizedName string `json:"sanitized_name"`
+	HeadSHA       string `json:"head_sha"`
+}
+
+// EventMove describes a "gw mv" operation.
+type EventMove struct {
+	OldBranch       string `json:"old_branch"`
+	NewBranch       string `json:"new_branch"`
+	OldWorktreePath string `json:"old_worktree_path"`
+	NewWorktreePath string `json:"new_worktree_path"`
+}
@@ -1,41 +1,334 @@
 package hook
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 )
 
-// Run executes a hook script if it exists.
-// Hook's stdout and stderr are both written to the output writer.
-// Returns nil if the hook file does not exist (success).
-// Returns an error if the hook file exists but is not executable, or if the hook exits non-zero.
-func Run(repoRoot, hookName, cwd, worktreePath, branch string, output io.Writer) error {
-	hookPath := filepath.Join(repoRoot, ".gw", "hooks", hookName)
+// Params describes a single hook invocation.
+type Params struct {
+	RepoRoot     string
+	Name         string // hook phase, e.g. "pre-add"
+	Dir          string // working directory of the hook process
+	WorktreePath string
+	Branch       string
+	GlobalDir    string        // user-global hooks directory; empty to run repository hooks only
+	Commands     []Command     // inline commands from config files
+	Trust        *TrustStore   // repository hooks must be trusted in this store; nil disables the check
+	Env          []string      // additional KEY=VALUE entries for hooks that need more context (e.g. pre-move)
+	Event        *Event        // payload written to stdin; the fields covered by Params are filled in by Run
+	Timeout      time.Duration // limit for the whole phase; zero means no timeout
+	Output       io.Writer     // receives both stdout and stderr of the hook
+}
+
+// Command is a hook command defined inline in a config file, run with "sh -c".
+type Command struct {
+	Run    string
+	Source string // config file that defines the command; repository commands are trusted through it
+	Global bool
+}
+
+// killGracePeriod is how long a hook is given to exit after SIGTERM before its process group is killed.
+const killGracePeriod = 5 * time.Second
+
+// Run executes the scripts of a hook phase: the single-file hook .gw/hooks/<name>,
+// the entries of .gw/hooks/<name>.d/ in lexical order, then the repository's inline commands.
+// Global hooks from GlobalDir and global inline commands are laid out the same way and run
+// before the repository hooks for pre-hooks and after them for post-hooks.
+// For pre-hooks, execution stops at the first failing script; for post-hooks, the remaining
+// scripts still run and all failures are returned joined with errors.Join.
+// Each error names the script that failed.
+//
+// Repository scripts are refused unless p.Trust records them with their current content;
+// repository inline commands are trusted through the config file that defines them.
+// Global scripts and commands are the user's own and are not checked.
+//
+// Every script receives the JSON encoding of p.Event on stdin. Scripts that do not read it are unaffected.
+//
+// Each script runs in its own process group; when ctx is canceled or the timeout expires,
+// the whole group is terminated so that no child processes are left behind.
+// If gw is in the foreground of a terminal, the group is put in the foreground while the
+// script runs so that it can prompt there.
+// Returns nil if the phase has no scripts (success).
+func Run(ctx context.Context, p Params) error {
+	isPost := strings.HasPrefix(p.Name, "post-")
 
-	info, err := os.Stat(hookPath)
-	if os.IsNotExist(err) {
+	scripts, err := collectScripts(p)
+	if err != nil {
+		return err
+	}
+	if len(scripts) == 0 {
 		return nil
 	}
+
+	var ev Event
+	if p.Event != nil {
+		ev = *p.Event
+	}
+	ev.Version = EventVersion
+	ev.Hook = p.Name
+	ev.Repo.Root = p.RepoRoot
+	ev.Worktree.Path = p.WorktreePath
+	ev.Branch = p.Branch
+	if ev.Flags == nil {
+		ev.Flags = map[string]bool{}
+	}
+	if ev.Outputs == nil {
+		ev.Outputs = map[string]string{}
+	}
+	payload, err := json.Marshal(ev)
 	if err != nil {
 		return err
 	}
 
-	if info.Mode()&0111 == 0 {
-		return fmt.Errorf("hook %q is not executable", hookName)
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	var errs []error
+	for _, script := range scripts {
+		if err := runScript(ctx, p, script, payload); err != nil {
+			if !isPost {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HasScripts reports whether the hook phase p.Name has any script or inline command to run.
+func HasScripts(p Params) (bool, error) {
+	scripts, err := collectScripts(p)
+	return len(scripts) > 0, err
+}
+
+// Script describes a script or inline command of a hook phase and whether it can run.
+type Script struct {
+	Name    string // as shown in errors, e.g. "post-add.d/10-npm" or ".gw/config: npm ci"
+	Path    string // the script, or the config file defining the command
+	Command string // set for inline commands
+	Global  bool
+	Problem string // why Run would refuse it, e.g. "not trusted"; empty if it can run
+}
+
+// Scripts returns the scripts and inline commands of the hook phase p.Name in execution order,
+// checked the way Run checks them before execution.
+func Scripts(p Params) ([]Script, error) {
+	scripts, err := collectScripts(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []Script
+	for _, s := range scripts {
+		info := Script{Name: s.name, Path: s.path, Command: s.command, Global: s.global}
+		if err := checkScript(p, s); err != nil {
+			var re *refusedError
+			if !errors.As(err, &re) {
+				return nil, err
+			}
+			info.Problem = re.problem
+		}
+		infos = append(infos, info)
 	}
+	return infos, nil
+}
 
-	cmd := exec.Command(hookPath)
-	cmd.Dir = cwd
-	cmd.Stdout = output
-	cmd.Stderr = output
+// collectScripts returns the repository and global scripts and inline commands of a hook phase in execution order.
+func collectScripts(p Params) ([]script, error) {
+	scripts, err := findScripts(filepath.Join(p.RepoRoot, ".gw", "hooks"), p.Name, false)
+	if err != nil {
+		return nil, err
+	}
+	var global []script
+	if p.GlobalDir != "" {
+		global, err = findScripts(p.GlobalDir, p.Name, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range p.Commands {
+		s := script{path: c.Source, command: c.Run, global: c.Global}
+		if c.Global {
+			s.name = c.Source + ": " + c.Run
+			global = append(global, s)
+		} else {
+			rel, err := filepath.Rel(p.RepoRoot, c.Source)
+			if err != nil {
+				rel = c.Source
+			}
+			s.name = filepath.ToSlash(rel) + ": " + c.Run
+			scripts = append(scripts, s)
+		}
+	}
+
+	if strings.HasPrefix(p.Name, "post-") {
+		return append(scripts, global...), nil
+	}
+	return append(global, scripts...), nil
+}
+
+// script is a hook script or inline command to execute.
+type script struct {
+	path    string // the script, or the config file defining the command
+	name    string // shown in errors: relative to .gw/hooks for repository hooks, absolute for global hooks, "<config>: <command>" for inline commands
+	command string // set for inline commands
+	global  bool
+}
+
+// findScripts returns the scripts of a hook phase in hooksDir in execution order.
+// Hidden entries and subdirectories of <name>.d/ are ignored.
+func findScripts(hooksDir, name string, global bool) ([]script, error) {
+	newScript := func(rel string) script {
+		path := filepath.Join(hooksDir, filepath.FromSlash(rel))
+		if global {
+			return script{path: path, name: path, global: true}
+		}
+		return script{path: path, name: rel}
+	}
+
+	var scripts []script
+	info, err := os.Stat(filepath.Join(hooksDir, name))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil && !info.IsDir() {
+		scripts = append(scripts, newScript(name))
+	}
+
+	dirName := name + ".d"
+	entries, err := os.ReadDir(filepath.Join(hooksDir, dirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") || e.IsDir() {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		scripts = append(scripts, newScript(dirName+"/"+n))
+	}
+
+	return scripts, nil
+}
+
+// refusedError reports a script that is not run because of its state rather than a failure to run it.
+type refusedError struct {
+	problem string // short description for "gw hook list", e.g. "not trusted"
+	msg     string
+}
+
+func (e *refusedError) Error() string { return e.msg }
+
+// checkScript verifies that a script is executable and, for repository scripts, trusted.
+func checkScript(p Params, s script) error {
+	if s.command == "" {
+		info, err := os.Stat(s.path)
+		if err != nil {
+			return err
+		}
+		if info.Mode()&0111 == 0 {
+			return &refusedError{"not executable", fmt.Sprintf("hook %q is not executable", s.name)}
+		}
+	}
+
+	if p.Trust != nil && !s.global {
+		state, err := p.Trust.check(s.path)
+		if err != nil {
+			return err
+		}
+		switch state {
+		case untrusted:
+			return &refusedError{"not trusted", fmt.Sprintf("hook %q is not trusted; review it and run \"gw hook trust\"", s.name)}
+		case modified:
+			return &refusedError{"modified since trusted", fmt.Sprintf("hook %q has been modified since it was trusted; review it and run \"gw hook trust\"", s.name)}
+		}
+	}
+	return nil
+}
+
+// runScript executes a single hook script or inline command.
+func runScript(ctx context.Context, p Params, s script, payload []byte) error {
+	if err := checkScript(p, s); err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if s.command != "" {
+		cmd = exec.CommandContext(ctx, "sh", "-c", s.command)
+	} else {
+		cmd = exec.CommandContext(ctx, s.path)
+	}
+	cmd.Dir = p.Dir
+	// Hooks that exit without reading stdin are unaffected: exec ignores the resulting EPIPE.
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = p.Output
+	cmd.Stderr = p.Output
 	cmd.Env = append(os.Environ(),
-		"GW_REPO_ROOT="+repoRoot,
-		"GW_WORKTREE_PATH="+worktreePath,
-		"GW_BRANCH="+branch,
+		"GW_REPO_ROOT="+p.RepoRoot,
+		"GW_WORKTREE_PATH="+p.WorktreePath,
+		"GW_BRANCH="+p.Branch,
+		"GW_HOOK_NAME="+p.Name,
 	)
+	cmd.Env = append(cmd.Env, p.Env...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	// A background process group cannot read from the terminal, so a hook that prompts
+	// (sudo, ssh passphrases, read </dev/tty) would be stopped. Its group takes over
+	// the terminal while it runs; Ctrl-C then reaches the hook instead of gw.
+	tty := foregroundTerminal()
+	if tty != nil {
+		defer tty.Close()
+		cmd.SysProcAttr.Foreground = true
+		cmd.SysProcAttr.Ctty = int(tty.Fd())
+	}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = killGracePeriod
+
+	err := cmd.Run()
+	if tty != nil {
+		if err := reclaimTerminal(tty); err != nil {
+			return fmt.Errorf("%s: failed to restore the terminal: %w", s.name, err)
+		}
+		// Pass Ctrl-C on to gw, which would have received it without the hook in the foreground.
+		if ps := cmd.ProcessState; ps != nil && ps.Sys().(syscall.WaitStatus).Signal() == syscall.SIGINT {
+			_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+			return fmt.Errorf("hook %q was interrupted: %w", s.name, context.Canceled)
+		}
+	}
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == nil {
+		return fmt.Errorf("%s: %w", s.name, err)
+	}
 
-	return cmd.Run()
+	// Make sure nothing the hook started outlives it.
+	if cmd.Process != nil {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && p.Timeout > 0 {
+		return fmt.Errorf("hook %q timed out after %s", s.name, p.Timeout)
+	}
+	return fmt.Errorf("hook %q was interrupted: %w", s.name, ctx.Err())
 }
@@ -2,10 +2,16 @@ package hook_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/gin0606/gw/internal/hook"
 	"github.com/gin0606/gw/internal/testutil"
@@ -14,7 +20,14 @@ import (
 func TestRun_HookNotExists(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 
-	err := hook.Run(repo.Root, "pre-add", repo.Root, "/some/path", "main", &bytes.Buffer{})
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &bytes.Buffer{},
+	})
 	if err != nil {
 		t.Errorf("expected no error for missing hook, got: %v", err)
 	}
@@ -24,7 +37,14 @@ func TestRun_HookExistsExitZero(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 	repo.WriteHook("pre-add", "#!/bin/sh\nexit 0\n")
 
-	err := hook.Run(repo.Root, "pre-add", repo.Root, "/some/path", "main", &bytes.Buffer{})
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &bytes.Buffer{},
+	})
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -34,7 +54,14 @@ func TestRun_HookNotExecutable(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 	repo.WriteHookNoExec("pre-add", "#!/bin/sh\nexit 0\n")
 
-	err := hook.Run(repo.Root, "pre-add", repo.Root, "/some/path", "main", &bytes.Buffer{})
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &bytes.Buffer{},
+	})
 	if err == nil {
 		t.Error("expected error for non-executable hook")
 	}
@@ -44,7 +71,14 @@ func TestRun_HookNonZeroExit(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 	repo.WriteHook("pre-add", "#!/bin/sh\nexit 1\n")
 
-	err := hook.Run(repo.Root, "pre-add", repo.Root, "/some/path", "main", &bytes.Buffer{})
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &bytes.Buffer{},
+	})
 	if err == nil {
 		t.Error("expected error for non-zero exit")
 	}
@@ -57,11 +91,19 @@ func TestRun_EnvironmentVariables(t *testing.T) {
 	repo.WriteHook("pre-add", "#!/bin/sh\n"+
 		"echo \"REPO_ROOT=$GW_REPO_ROOT\" >> "+outFile+"\n"+
 		"echo \"WORKTREE_PATH=$GW_WORKTREE_PATH\" >> "+outFile+"\n"+
-		"echo \"BRANCH=$GW_BRANCH\" >> "+outFile+"\n")
+		"echo \"BRANCH=$GW_BRANCH\" >> "+outFile+"\n"+
+		"echo \"HOOK_NAME=$GW_HOOK_NAME\" >> "+outFile+"\n")
 
 	wtPath := "/expected/worktree/path"
 	branch := "feature/test"
-	err := hook.Run(repo.Root, "pre-add", repo.Root, wtPath, branch, &bytes.Buffer{})
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: wtPath,
+		Branch:       branch,
+		Output:       &bytes.Buffer{},
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -81,6 +123,38 @@ func TestRun_EnvironmentVariables(t *testing.T) {
 	if !strings.Contains(content, "BRANCH="+branch) {
 		t.Errorf("GW_BRANCH not set correctly:\n%s", content)
 	}
+	if !strings.Contains(content, "HOOK_NAME=pre-add") {
+		t.Errorf("GW_HOOK_NAME not set correctly:\n%s", content)
+	}
+}
+
+func TestRun_ExtraEnvironmentVariables(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	outFile := filepath.Join(t.TempDir(), "env.txt")
+
+	repo.WriteHook("pre-move", "#!/bin/sh\n"+
+		"echo \"OLD=$GW_OLD_BRANCH NEW=$GW_NEW_BRANCH\" > "+outFile+"\n")
+
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-move",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "old",
+		Env:          []string{"GW_OLD_BRANCH=old", "GW_NEW_BRANCH=new"},
+		Output:       &bytes.Buffer{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "OLD=old NEW=new" {
+		t.Errorf("got %q, want %q", got, "OLD=old NEW=new")
+	}
 }
 
 func TestRun_Cwd(t *testing.T) {
@@ -89,7 +163,14 @@ func TestRun_Cwd(t *testing.T) {
 
 	repo.WriteHook("pre-add", "#!/bin/sh\npwd -P > "+outFile+"\n")
 
-	err := hook.Run(repo.Root, "pre-add", repo.Root, "/some/path", "main", &bytes.Buffer{})
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &bytes.Buffer{},
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -109,7 +190,14 @@ func TestRun_StdoutToOutput(t *testing.T) {
 	repo.WriteHook("pre-add", "#!/bin/sh\necho 'hook stdout'\n")
 
 	var buf bytes.Buffer
-	err := hook.Run(repo.Root, "pre-add", repo.Root, "/some/path", "main", &buf)
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &buf,
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -124,7 +212,14 @@ func TestRun_StderrToOutput(t *testing.T) {
 	repo.WriteHook("pre-add", "#!/bin/sh\necho 'hook stderr' >&2\n")
 
 	var buf bytes.Buffer
-	err := hook.Run(repo.Root, "pre-add", repo.Root, "/some/path", "main", &buf)
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &buf,
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -133,3 +228,600 @@ func TestRun_StderrToOutput(t *testing.T) {
 		t.Errorf("expected hook stderr in output, got: %q", buf.String())
 	}
 }
+
+func TestRun_Timeout(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	// The background sleep checks that the whole process group is killed, not just the hook.
+	repo.WriteHook("post-add", "#!/bin/sh\nsleep 30 &\necho $! > "+pidFile+"\nwait\n")
+
+	start := time.Now()
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "post-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Timeout:      200 * time.Millisecond,
+		Output:       &bytes.Buffer{},
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("hook was not terminated promptly (took %v)", elapsed)
+	}
+	assertProcessGone(t, pidFile)
+}
+
+func TestRun_Canceled(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	repo.WriteHook("pre-add", "#!/bin/sh\nsleep 30 &\necho $! > "+pidFile+"\nwait\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	err := hook.Run(ctx, hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &bytes.Buffer{},
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Fatalf("expected interrupted error, got: %v", err)
+	}
+	assertProcessGone(t, pidFile)
+}
+
+func TestRun_FinishesWithinTimeout(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("pre-add", "#!/bin/sh\nexit 0\n")
+
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Timeout:      10 * time.Second,
+		Output:       &bytes.Buffer{},
+	})
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+// assertProcessGone waits briefly for the process whose PID is stored in pidFile to disappear.
+func assertProcessGone(t *testing.T, pidFile string) {
+	t.Helper()
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for syscall.Kill(pid, 0) == nil {
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("child process %d of the hook is still running", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestRun_HookDirectory_LexicalOrder(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	logFile := filepath.Join(t.TempDir(), "order.txt")
+	script := func(name string) string {
+		return "#!/bin/sh\necho " + name + " >> " + logFile + "\n"
+	}
+	repo.WriteHook("post-add", script("single"))
+	repo.WriteHook("post-add.d/20-docker", script("20-docker"))
+	repo.WriteHook("post-add.d/10-npm", script("10-npm"))
+	repo.WriteHook("post-add.d/30-env", script("30-env"))
+	repo.WriteHook("post-add.d/.hidden", script("hidden"))
+
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "post-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &bytes.Buffer{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "single\n10-npm\n20-docker\n30-env\n"
+	if string(data) != want {
+		t.Errorf("execution order = %q, want %q", string(data), want)
+	}
+}
+
+func TestRun_HookDirectory_PreHookStopsOnFailure(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	marker := filepath.Join(t.TempDir(), "ran")
+	repo.WriteHook("pre-add.d/10-fail", "#!/bin/sh\nexit 3\n")
+	repo.WriteHook("pre-add.d/20-after", "#!/bin/sh\ntouch "+marker+"\n")
+
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &bytes.Buffer{},
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "pre-add.d/10-fail") {
+		t.Fatalf("expected error naming pre-add.d/10-fail, got: %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("scripts after a failing pre-hook should not run")
+	}
+}
+
+func TestRun_HookDirectory_PostHookContinuesOnFailure(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	marker := filepath.Join(t.TempDir(), "ran")
+	repo.WriteHook("post-add.d/10-fail", "#!/bin/sh\nexit 1\n")
+	repo.WriteHook("post-add.d/20-after", "#!/bin/sh\ntouch "+marker+"\n")
+	repo.WriteHookNoExec("post-add.d/30-noexec", "#!/bin/sh\nexit 0\n")
+
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "post-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &bytes.Buffer{},
+	})
+
+	if err == nil {
+		t.Fatal("expected error for failing post-hook scripts")
+	}
+	if !strings.Contains(err.Error(), "post-add.d/10-fail") || !strings.Contains(err.Error(), "post-add.d/30-noexec") {
+		t.Errorf("expected error naming both failing scripts, got: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("scripts after a failing post-hook should still run")
+	}
+}
+
+func TestRun_GlobalHooks_Order(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	globalDir := t.TempDir()
+	logFile := filepath.Join(t.TempDir(), "order.txt")
+	script := func(name string) []byte {
+		return []byte("#!/bin/sh\necho " + name + " >> " + logFile + "\n")
+	}
+	for _, phase := range []string{"pre-add", "post-add"} {
+		repo.WriteHook(phase, string(script("repo-"+phase)))
+		if err := os.MkdirAll(filepath.Join(globalDir, phase+".d"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(globalDir, phase+".d", "10-global"), script("global-"+phase), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, phase := range []string{"pre-add", "post-add"} {
+		err := hook.Run(context.Background(), hook.Params{
+			RepoRoot:     repo.Root,
+			Name:         phase,
+			Dir:          repo.Root,
+			WorktreePath: "/some/path",
+			Branch:       "main",
+			GlobalDir:    globalDir,
+			Output:       &bytes.Buffer{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "global-pre-add\nrepo-pre-add\nrepo-post-add\nglobal-post-add\n"
+	if string(data) != want {
+		t.Errorf("execution order = %q, want %q", string(data), want)
+	}
+}
+
+func TestRun_GlobalHooks_FailureNamesPath(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	globalDir := t.TempDir()
+	globalHook := filepath.Join(globalDir, "pre-add")
+	if err := os.WriteFile(globalHook, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	marker := filepath.Join(t.TempDir(), "ran")
+	repo.WriteHook("pre-add", "#!/bin/sh\ntouch "+marker+"\n")
+
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		GlobalDir:    globalDir,
+		Output:       &bytes.Buffer{},
+	})
+
+	if err == nil || !strings.Contains(err.Error(), globalHook) {
+		t.Fatalf("expected error naming %s, got: %v", globalHook, err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("repository pre-add hook should not run after the global one failed")
+	}
+}
+
+func TestRun_Trust(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	store := &hook.TrustStore{Path: filepath.Join(t.TempDir(), "trust")}
+	repo.WriteHook("pre-add", "#!/bin/sh\nexit 0\n")
+	hookPath := filepath.Join(repo.Root, ".gw", "hooks", "pre-add")
+	params := hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Trust:        store,
+		Output:       &bytes.Buffer{},
+	}
+
+	if err := hook.Run(context.Background(), params); err == nil || !strings.Contains(err.Error(), "not trusted") {
+		t.Fatalf("expected untrusted error, got: %v", err)
+	}
+
+	if err := store.Trust(hookPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := hook.Run(context.Background(), params); err != nil {
+		t.Fatalf("expected trusted hook to run, got: %v", err)
+	}
+
+	repo.WriteHook("pre-add", "#!/bin/sh\necho changed\n")
+	if err := hook.Run(context.Background(), params); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Fatalf("expected modified error, got: %v", err)
+	}
+
+	if err := store.Trust(hookPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Untrust(hookPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := hook.Run(context.Background(), params); err == nil || !strings.Contains(err.Error(), "not trusted") {
+		t.Fatalf("expected untrusted error after untrust, got: %v", err)
+	}
+}
+
+func TestRun_Trust_GlobalHooksNotChecked(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	globalDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(globalDir, "pre-add"), []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		GlobalDir:    globalDir,
+		Trust:        &hook.TrustStore{Path: filepath.Join(t.TempDir(), "trust")},
+		Output:       &bytes.Buffer{},
+	})
+	if err != nil {
+		t.Errorf("global hooks should not require trust, got: %v", err)
+	}
+}
+
+func TestRun_Commands_Order(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	globalDir := t.TempDir()
+	logFile := filepath.Join(t.TempDir(), "order.txt")
+	repoConfig := filepath.Join(repo.Root, ".gw", "config")
+	globalConfig := filepath.Join(globalDir, "config")
+	for _, phase := range []string{"pre-add", "post-add"} {
+		repo.WriteHook(phase, "#!/bin/sh\necho repo-file >> "+logFile+"\n")
+		if err := os.WriteFile(filepath.Join(globalDir, phase), []byte("#!/bin/sh\necho global-file >> "+logFile+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, phase := range []string{"pre-add", "post-add"} {
+		err := hook.Run(context.Background(), hook.Params{
+			RepoRoot:     repo.Root,
+			Name:         phase,
+			Dir:          repo.Root,
+			WorktreePath: "/some/path",
+			Branch:       "main",
+			GlobalDir:    globalDir,
+			Commands: []hook.Command{
+				{Run: "echo global-command >> " + logFile, Source: globalConfig, Global: true},
+				{Run: "echo \"repo-command $GW_HOOK_NAME\" >> " + logFile, Source: repoConfig},
+			},
+			Output: &bytes.Buffer{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "global-file\nglobal-command\nrepo-file\nrepo-command pre-add\n" +
+		"repo-file\nrepo-command post-add\nglobal-file\nglobal-command\n"
+	if string(data) != want {
+		t.Errorf("execution order = %q, want %q", string(data), want)
+	}
+}
+
+func TestRun_Commands_FailureNamesCommand(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Commands:     []hook.Command{{Run: "exit 3", Source: filepath.Join(repo.Root, ".gw", "config")}},
+		Output:       &bytes.Buffer{},
+	})
+
+	if err == nil || !strings.Contains(err.Error(), ".gw/config: exit 3") {
+		t.Errorf("expected error naming the command, got: %v", err)
+	}
+}
+
+func TestRun_Commands_TrustedThroughConfig(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	store := &hook.TrustStore{Path: filepath.Join(t.TempDir(), "trust")}
+	repo.WriteConfig("[hooks]\npre-add = [\"true\"]\n")
+	configPath := filepath.Join(repo.Root, ".gw", "config")
+	params := hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Commands:     []hook.Command{{Run: "true", Source: configPath}},
+		Trust:        store,
+		Output:       &bytes.Buffer{},
+	}
+
+	if err := hook.Run(context.Background(), params); err == nil || !strings.Contains(err.Error(), "not trusted") {
+		t.Fatalf("expected untrusted error, got: %v", err)
+	}
+	if err := store.Trust(configPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := hook.Run(context.Background(), params); err != nil {
+		t.Fatalf("expected trusted command to run, got: %v", err)
+	}
+
+	params.Commands = []hook.Command{{Run: "true", Source: filepath.Join(t.TempDir(), "config"), Global: true}}
+	params.Trust = &hook.TrustStore{Path: filepath.Join(t.TempDir(), "trust")}
+	if err := hook.Run(context.Background(), params); err != nil {
+		t.Errorf("global commands should not require trust, got: %v", err)
+	}
+}
+
+func TestScripts(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	store := &hook.TrustStore{Path: filepath.Join(t.TempDir(), "trust")}
+	repo.WriteHook("post-add", "#!/bin/sh\n")
+	repo.WriteHookNoExec("post-add.d/10-noexec", "#!/bin/sh\n")
+	repo.WriteHook("post-add.d/20-trusted", "#!/bin/sh\n")
+	if err := store.Trust(filepath.Join(repo.Root, ".gw", "hooks", "post-add.d", "20-trusted")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := hook.Scripts(hook.Params{
+		RepoRoot: repo.Root,
+		Name:     "post-add",
+		Commands: []hook.Command{{Run: "npm ci", Source: filepath.Join(t.TempDir(), "config"), Global: true}},
+		Trust:    store,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var states []string
+	for _, s := range got {
+		states = append(states, s.Name+"="+s.Problem)
+	}
+	want := []string{
+		"post-add=not trusted",
+		"post-add.d/10-noexec=not executable",
+		"post-add.d/20-trusted=",
+		got[len(got)-1].Path + ": npm ci=",
+	}
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("got %q, want %q", states, want)
+	}
+}
+
+func TestRepoScripts(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.WriteHook("post-add", "#!/bin/sh\n")
+	repo.WriteHook("post-add.d/10-npm", "#!/bin/sh\n")
+	repo.WriteHook("post-add.d/.hidden", "#!/bin/sh\n")
+	repo.WriteHook("pre-remove", "#!/bin/sh\n")
+
+	got, err := hook.RepoScripts(repo.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooksDir := filepath.Join(repo.Root, ".gw", "hooks")
+	want := []string{
+		filepath.Join(hooksDir, "post-add"),
+		filepath.Join(hooksDir, "post-add.d", "10-npm"),
+		filepath.Join(hooksDir, "pre-remove"),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRun_EventOnStdin(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	outFile := filepath.Join(t.TempDir(), "event.json")
+	repo.WriteHook("pre-remove", "#!/bin/sh\ncat > "+outFile+"\n")
+
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-remove",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "feature/x",
+		Event: &hook.Event{
+			GwVersion: "1.2.3",
+			Repo:      hook.EventRepo{Name: "repo"},
+			Worktree:  hook.EventWorktree{SanitizedName: "feature-x", HeadSHA: "abc123"},
+			Flags:     map[string]bool{"force": true},
+			Outputs:   map[string]string{"port": "3000"},
+		},
+		Output: &bytes.Buffer{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got hook.Event
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid JSON on stdin: %v\n%s", err, data)
+	}
+	want := hook.Event{
+		Version:   hook.EventVersion,
+		Hook:      "pre-remove",
+		GwVersion: "1.2.3",
+		Repo:      hook.EventRepo{Root: repo.Root, Name: "repo"},
+		Worktree:  hook.EventWorktree{Path: "/some/path", SanitizedName: "feature-x", HeadSHA: "abc123"},
+		Branch:    "feature/x",
+		Flags:     map[string]bool{"force": true},
+		Outputs:   map[string]string{"port": "3000"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestRun_EventWithoutParams(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	outFile := filepath.Join(t.TempDir(), "event.json")
+	repo.WriteHook("pre-add", "#!/bin/sh\ncat > "+outFile+"\n")
+
+	err := hook.Run(context.Background(), hook.Params{
+		RepoRoot:     repo.Root,
+		Name:         "pre-add",
+		Dir:          repo.Root,
+		WorktreePath: "/some/path",
+		Branch:       "main",
+		Output:       &bytes.Buffer{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"version":1`) || !strings.Contains(string(data), `"flags":{}`) ||
+		!strings.Contains(string(data), `"outputs":{}`) {
+		t.Errorf("unexpected event: %s", data)
+	}
+}
+
+func TestRun_HookIgnoringStdin(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	// A payload larger than the pipe buffer must not block a hook that never reads it
+	repo.WriteHook("post-add", "#!/bin/sh\nexit 0\n")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.Run(context.Background(), hook.Params{
+			RepoRoot:     repo.Root,
+			Name:         "post-add",
+			Dir:          repo.Root,
+			WorktreePath: "/some/path",
+			Branch:       "main",
+			Event:        &hook.Event{BaseRef: strings.Repeat("x", 1<<20)},
+			Output:       &bytes.Buffer{},
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("hook.Run blocked on a hook that does not read stdin")
+	}
+}
+
+func TestReadOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outputs")
+	if err := os.WriteFile(path, []byte("port=3000\n\nurl=http://localhost:3000/?a=b\nPORT=3001\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := hook.ReadOutputs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"port": "3001", "url": "http://localhost:3000/?a=b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestReadOutputs_Missing(t *testing.T) {
+	got, err := hook.ReadOutputs(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no outputs, got %v", got)
+	}
+}
+
+func TestReadOutputs_Invalid(t *testing.T) {
+	for _, content := range []string{"no-separator\n", "bad-key=1\n", "=1\n", "FILE=x\n"} {
+		path := filepath.Join(t.TempDir(), "outputs")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := hook.ReadOutputs(path); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package hook
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// outputKeyPattern restricts output keys to names that can also be used in environment variables.
+var outputKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ReadOutputs parses the file hooks write their outputs to (GW_OUTPUT_FILE).
+// Each non-empty line is a key=value pair; later lines override earlier ones.
+// Keys are lowercased, since they are passed to hooks as GW_OUTPUT_<KEY> and must not differ only by case.
+// Returns an empty map if the file does not exist.
+func ReadOutputs(path string) (map[string]string, error) {
+	outputs := map[string]string{}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return outputs, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || !outputKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid output on line %d: %q (expected key=value)", n, line)
+		}
+		// Outputs are passed to later hooks as GW_OUTPUT_<KEY>, which must not shadow GW_OUTPUT_FILE.
+		if strings.EqualFold(key, "file") {
+			return nil, fmt.Errorf("invalid output on line %d: key %q is reserved", n, key)
+		}
+		outputs[strings.ToLower(key)] = value
+	}
+	return outputs, sc.Err()
+}
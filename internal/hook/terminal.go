@@ -0,0 +1,46 @@
+package hook
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// foregroundTerminal returns gw's controlling terminal if gw's process group is in its foreground,
+// or nil otherwise, e.g. without a terminal or when gw runs as a background job.
+func foregroundTerminal() *os.File {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil
+	}
+	pgrp, err := foregroundGroup(tty)
+	if err != nil || pgrp != syscall.Getpgrp() {
+		tty.Close()
+		return nil
+	}
+	return tty
+}
+
+// foregroundGroup returns the foreground process group of the terminal tty.
+func foregroundGroup(tty *os.File) (int, error) {
+	var pgrp int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, tty.Fd(), syscall.TIOCGPGRP, uintptr(unsafe.Pointer(&pgrp))); errno != 0 {
+		return 0, errno
+	}
+	return int(pgrp), nil
+}
+
+// reclaimTerminal makes gw's process group the foreground process group of tty again
+// after a hook has run in the foreground.
+func reclaimTerminal(tty *os.File) error {
+	// Changing the foreground group from a background group raises SIGTTOU, which would stop gw.
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+
+	pgrp := int32(syscall.Getpgrp())
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, tty.Fd(), syscall.TIOCSPGRP, uintptr(unsafe.Pointer(&pgrp))); errno != 0 {
+		return errno
+	}
+	return nil
+}
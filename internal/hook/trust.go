@@ -0,0 +1,202 @@
+package hook
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// TrustStore records which repository hook scripts the user has reviewed.
+// Each line of the file holds the SHA-256 of a script's content and its absolute path,
+// in the format of sha256sum. A script is trusted only while its content matches.
+type TrustStore struct {
+	Path string
+}
+
+// trustState is the result of looking up a script in the trust store.
+type trustState int
+
+const (
+	untrusted trustState = iota
+	trusted
+	modified // trusted once, but its content changed since
+)
+
+// Trust records the current content of the scripts at paths as trusted.
+func (s TrustStore) Trust(paths ...string) error {
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		key, err := trustKey(p)
+		if err != nil {
+			return err
+		}
+		sum, err := hashFile(key)
+		if err != nil {
+			return err
+		}
+		entries[key] = sum
+	}
+	return s.save(entries)
+}
+
+// Untrust removes the scripts at paths from the trust store.
+func (s TrustStore) Untrust(paths ...string) error {
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		key, err := trustKey(p)
+		if err != nil {
+			return err
+		}
+		delete(entries, key)
+	}
+	return s.save(entries)
+}
+
+// check reports whether the script at path is trusted with its current content.
+func (s TrustStore) check(path string) (trustState, error) {
+	entries, err := s.load()
+	if err != nil {
+		return untrusted, err
+	}
+	path, err = trustKey(path)
+	if err != nil {
+		return untrusted, err
+	}
+	want, ok := entries[path]
+	if !ok {
+		return untrusted, nil
+	}
+	sum, err := hashFile(path)
+	if err != nil {
+		return untrusted, err
+	}
+	if sum != want {
+		return modified, nil
+	}
+	return trusted, nil
+}
+
+func (s TrustStore) load() (map[string]string, error) {
+	entries := map[string]string{}
+
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return entries, nil
+		}
+		return nil, err
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		sum, path, ok := strings.Cut(sc.Text(), "  ")
+		if !ok {
+			continue
+		}
+		entries[path] = sum
+	}
+	return entries, sc.Err()
+}
+
+func (s TrustStore) save(entries map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, path := range slices.Sorted(maps.Keys(entries)) {
+		fmt.Fprintf(&buf, "%s  %s\n", entries[path], path)
+	}
+
+	// Write to a temporary file and rename so that a crash never leaves a truncated store.
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".trust-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// trustKey returns the key of a script in the trust store: its absolute path with
+// symlinks in the directory part resolved, so that the same script is found
+// regardless of how the repository was reached.
+func trustKey(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err != nil {
+		return abs, nil
+	}
+	return filepath.Join(dir, filepath.Base(abs)), nil
+}
+
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// RepoScripts returns the absolute paths of all hook scripts in the repository's .gw/hooks/,
+// including the entries of <phase>.d/ directories. Hidden entries are ignored.
+func RepoScripts(repoRoot string) ([]string, error) {
+	hooksDir := filepath.Join(repoRoot, ".gw", "hooks")
+
+	entries, err := os.ReadDir(hooksDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if !e.IsDir() {
+			paths = append(paths, filepath.Join(hooksDir, e.Name()))
+			continue
+		}
+		if !strings.HasSuffix(e.Name(), ".d") {
+			continue
+		}
+		sub, err := os.ReadDir(filepath.Join(hooksDir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, se := range sub {
+			if strings.HasPrefix(se.Name(), ".") || se.IsDir() {
+				continue
+			}
+			paths = append(paths, filepath.Join(hooksDir, e.Name(), se.Name()))
+		}
+	}
+	return paths, nil
+}
@@ -1,23 +1,130 @@
 package pathutil
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
 	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
 )
 
-// Sanitize converts a branch name to a filesystem-safe directory name.
+// Sanitization strategies, selecting how Sanitizer turns a branch name into a directory name.
+const (
+	StrategyFlatten    = "flatten"     // "/" becomes "-": feature/x -> feature-x
+	StrategyNested     = "nested"      // "/" separates directories: feature/x -> feature/x
+	StrategySlug       = "slug"        // lowercase ASCII letters and digits joined by "-": Feature/Fix_Bug -> feature-fix-bug
+	StrategyHashSuffix = "hash-suffix" // flatten, then append a hash of the branch name: feature/x -> feature-x-1a2b3c4d
+)
+
+// Strategies lists the valid sanitization strategies.
+var Strategies = []string{StrategyFlatten, StrategyNested, StrategySlug, StrategyHashSuffix}
+
+// hashSuffixLen is the number of hex digits of the hash appended by StrategyHashSuffix.
+const hashSuffixLen = 8
+
+// Sanitizer converts branch names to filesystem-safe directory names.
+// The zero value uses StrategyFlatten without a length limit.
+type Sanitizer struct {
+	Strategy  string // one of Strategies; empty means StrategyFlatten
+	MaxLength int    // maximum length in bytes of each directory name; zero means no limit
+}
+
+// Sanitize converts a branch name to a filesystem-safe directory name with StrategyFlatten.
 // Rules: replace "/" with "-", then trim leading/trailing hyphens.
 func Sanitize(branch string) (string, error) {
-	s := strings.ReplaceAll(branch, "/", "-")
-	s = strings.Trim(s, "-")
+	return Sanitizer{}.Sanitize(branch)
+}
 
-	if s == "" || s == "." || s == ".." {
-		return "", fmt.Errorf("invalid branch name %q: sanitized result is %q", branch, s)
+// Sanitize converts a branch name to a directory name according to s.Strategy.
+// With StrategyNested, the result may consist of several directories separated by "/".
+// Names longer than s.MaxLength are truncated; StrategyHashSuffix keeps the hash when truncating.
+// It is an error if a resulting directory name is empty, "." or "..".
+func (s Sanitizer) Sanitize(branch string) (string, error) {
+	var segments []string
+	switch s.Strategy {
+	case "", StrategyFlatten:
+		segments = []string{s.truncate(flatten(branch))}
+	case StrategyNested:
+		for _, seg := range strings.Split(strings.Trim(branch, "/"), "/") {
+			segments = append(segments, s.truncate(seg))
+		}
+	case StrategySlug:
+		segments = []string{s.truncate(slug(branch))}
+	case StrategyHashSuffix:
+		name, err := s.hashSuffix(branch)
+		if err != nil {
+			return "", err
+		}
+		segments = []string{name}
+	default:
+		return "", fmt.Errorf("unknown sanitize strategy %q (valid: %s)", s.Strategy, strings.Join(Strategies, ", "))
 	}
 
-	return s, nil
+	result := strings.Join(segments, "/")
+	for _, seg := range segments {
+		if seg == "" || seg == "." || seg == ".." {
+			return "", fmt.Errorf("invalid branch name %q: sanitized result is %q", branch, result)
+		}
+	}
+	return result, nil
+}
+
+func flatten(branch string) string {
+	return strings.Trim(strings.ReplaceAll(branch, "/", "-"), "-")
+}
+
+func slug(branch string) string {
+	var sb strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(branch) {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			if sep && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			sep = false
+		} else {
+			sep = true
+		}
+	}
+	return sb.String()
+}
+
+// hashSuffix returns the flattened branch name followed by "-" and a hash of the full branch name,
+// shortening the name rather than the hash to fit s.MaxLength.
+func (s Sanitizer) hashSuffix(branch string) (string, error) {
+	sum := sha256.Sum256([]byte(branch))
+	suffix := "-" + hex.EncodeToString(sum[:])[:hashSuffixLen]
+
+	name := flatten(branch)
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid branch name %q: sanitized result is %q", branch, name)
+	}
+	if s.MaxLength > 0 {
+		if s.MaxLength <= len(suffix) {
+			return "", fmt.Errorf("max length %d is too short for the %s strategy; it must be greater than %d", s.MaxLength, StrategyHashSuffix, len(suffix))
+		}
+		name = Sanitizer{MaxLength: s.MaxLength - len(suffix)}.truncate(name)
+	}
+	return name + suffix, nil
+}
+
+// truncate shortens name to at most s.MaxLength bytes without splitting a UTF-8 sequence,
+// then trims trailing hyphens left by the cut.
+func (s Sanitizer) truncate(name string) string {
+	if s.MaxLength <= 0 || len(name) <= s.MaxLength {
+		return name
+	}
+	n := s.MaxLength
+	for n > 0 && !utf8.RuneStart(name[n]) {
+		n--
+	}
+	return strings.TrimRight(name[:n], "-")
 }
 
 // BaseDir resolves the worktree base directory from config or default.
@@ -31,21 +138,167 @@ func BaseDir(repoRoot, repoName, worktreesDir string) string {
 	return filepath.Join(repoRoot, worktreesDir)
 }
 
-// ComputePath returns the full worktree path for a branch.
+// DefaultPathTemplate is the path template used when path_template is not configured:
+// the sanitized branch name directly under the base directory.
+const DefaultPathTemplate = "{{.Branch | sanitize}}"
+
+// PathVars are the variables available in path templates.
+type PathVars struct {
+	RepoName        string
+	Branch          string
+	SanitizedBranch string   // empty if the branch cannot be sanitized
+	BranchSegments  []string // Branch split at "/"
+	User            string   // name of the current user
+	Date            string   // current date as YYYY-MM-DD
+}
+
+// NewPathVars returns the template variables for a branch of the repository repoName,
+// with SanitizedBranch converted by s.
+func NewPathVars(repoName, branch string, s Sanitizer) PathVars {
+	sanitized, _ := s.Sanitize(branch)
+	return PathVars{
+		RepoName:        repoName,
+		Branch:          branch,
+		SanitizedBranch: sanitized,
+		BranchSegments:  strings.Split(branch, "/"),
+		User:            currentUser(),
+		Date:            time.Now().Format(time.DateOnly),
+	}
+}
+
+func currentUser() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// ComputePath returns the full worktree path for a branch using DefaultPathTemplate.
 func ComputePath(baseDir, branch string) (string, error) {
-	sanitized, err := Sanitize(branch)
+	return RenderPath(baseDir, DefaultPathTemplate, PathVars{Branch: branch}, Sanitizer{})
+}
+
+// RenderPath renders the path template tmpl (text/template syntax, with a "sanitize" function
+// that converts its argument with s) and returns it joined to baseDir.
+// The rendered path must be relative and stay inside baseDir.
+func RenderPath(baseDir, tmpl string, vars PathVars, s Sanitizer) (string, error) {
+	var sanitizeErr error
+	t, err := template.New("path_template").Option("missingkey=error").Funcs(template.FuncMap{
+		"sanitize": func(str string) (string, error) {
+			v, err := s.Sanitize(str)
+			if err != nil {
+				sanitizeErr = err
+			}
+			return v, err
+		},
+	}).Parse(tmpl)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("invalid path_template %q: %w", tmpl, err)
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, vars); err != nil {
+		if sanitizeErr != nil {
+			return "", sanitizeErr
+		}
+		return "", fmt.Errorf("failed to render path_template %q: %w", tmpl, err)
+	}
+
+	rel := filepath.Clean(sb.String())
+	if !filepath.IsLocal(rel) || rel == "." {
+		return "", fmt.Errorf("path_template %q produced %q for branch %q, which is not a path inside %s", tmpl, sb.String(), vars.Branch, baseDir)
 	}
-	return filepath.Join(baseDir, sanitized), nil
+	return filepath.Join(baseDir, rel), nil
 }
 
 // ValidatePath checks that the target directory does not already exist.
-func ValidatePath(path string) error {
-	if _, err := os.Stat(path); err == nil {
-		return fmt.Errorf("directory already exists: %s", path)
+// owners maps the paths of existing worktrees to their branches ("" for detached worktrees);
+// the error names the worktree that holds the directory, e.g. when two branches sanitize to the same name.
+func ValidatePath(path string, owners map[string]string) error {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	if occ := Occupant(path, owners); occ != "" {
+		return fmt.Errorf("directory already exists: %s (%s)", path, occ)
+	}
+	return fmt.Errorf("directory already exists: %s", path)
+}
+
+// Occupant describes the worktree in owners that path belongs to, e.g.
+// `used by the worktree of branch "feature/x"`, or returns "" if path is not part of any worktree.
+// When path is not a worktree itself, the innermost worktree containing it is described.
+func Occupant(path string, owners map[string]string) string {
+	candidates := []string{path}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+		candidates = append(candidates, resolved)
+	}
+	var owner string
+	exact := false
+	for wtPath := range owners {
+		for _, p := range candidates {
+			switch {
+			case p == wtPath:
+				owner, exact = wtPath, true
+			case !exact && strings.HasPrefix(p, wtPath+string(filepath.Separator)) && len(wtPath) > len(owner):
+				owner = wtPath
+			}
+		}
+	}
+
+	if owner == "" {
+		return ""
+	}
+	desc := "a detached worktree"
+	if b := owners[owner]; b != "" {
+		desc = fmt.Sprintf("the worktree of branch %q", b)
+	}
+	if exact {
+		return "used by " + desc
+	}
+	return fmt.Sprintf("inside %s at %s", desc, owner)
+}
+
+// Collision policies, selecting what happens when the directory computed for a worktree already exists.
+const (
+	CollisionError        = "error"          // fail
+	CollisionSuffix       = "suffix"         // use the first free path among <path>-2, <path>-3, ...
+	CollisionReuseIfEmpty = "reuse-if-empty" // create the worktree in the directory if it is empty; fail otherwise
+)
+
+// CollisionPolicies lists the valid collision policies.
+var CollisionPolicies = []string{CollisionError, CollisionSuffix, CollisionReuseIfEmpty}
+
+// ResolveCollision returns the path to create a worktree at when the computed path is path,
+// applying policy if the directory already exists. An empty policy means CollisionError.
+// owners is as for ValidatePath; the returned error is that of ValidatePath when the collision cannot be resolved.
+func ResolveCollision(path, policy string, owners map[string]string) (string, error) {
+	err := ValidatePath(path, owners)
+	if err == nil {
+		return path, nil
+	}
+
+	switch policy {
+	case "", CollisionError:
+		return "", err
+	case CollisionSuffix:
+		for n := 2; ; n++ {
+			candidate := fmt.Sprintf("%s-%d", path, n)
+			if ValidatePath(candidate, owners) == nil {
+				return candidate, nil
+			}
+		}
+	case CollisionReuseIfEmpty:
+		entries, readErr := os.ReadDir(path)
+		if readErr != nil || len(entries) > 0 {
+			return "", err
+		}
+		return path, nil
+	default:
+		return "", fmt.Errorf("unknown collision policy %q (valid: %s)", policy, strings.Join(CollisionPolicies, ", "))
 	}
-	return nil
 }
 
 // EnsureBaseDir creates the base directory if it doesn't exist.
@@ -1,7 +1,9 @@
 package pathutil_test
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gin0606/gw/internal/pathutil"
@@ -98,15 +100,268 @@ func TestComputePath(t *testing.T) {
 
 func TestValidatePath_Exists(t *testing.T) {
 	dir := t.TempDir()
-	err := pathutil.ValidatePath(dir)
+	err := pathutil.ValidatePath(dir, nil)
 	if err == nil {
 		t.Error("expected error for existing directory")
 	}
 }
 
+func TestValidatePath_NamesOwner(t *testing.T) {
+	base := t.TempDir()
+	wtPath := filepath.Join(base, "feat-a-b")
+	if err := os.MkdirAll(filepath.Join(wtPath, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	owners := map[string]string{base: "main", wtPath: "feat/a-b"}
+
+	err := pathutil.ValidatePath(wtPath, owners)
+	if err == nil || !strings.Contains(err.Error(), `used by the worktree of branch "feat/a-b"`) {
+		t.Errorf("expected error naming the owning branch, got: %v", err)
+	}
+
+	err = pathutil.ValidatePath(filepath.Join(wtPath, "sub"), owners)
+	if err == nil || !strings.Contains(err.Error(), `inside the worktree of branch "feat/a-b"`) {
+		t.Errorf("expected error naming the innermost containing worktree, got: %v", err)
+	}
+
+	err = pathutil.ValidatePath(wtPath, map[string]string{wtPath: ""})
+	if err == nil || !strings.Contains(err.Error(), "used by a detached worktree") {
+		t.Errorf("expected error naming the detached worktree, got: %v", err)
+	}
+}
+
 func TestValidatePath_NotExists(t *testing.T) {
-	err := pathutil.ValidatePath("/nonexistent/path/that/does/not/exist")
+	err := pathutil.ValidatePath("/nonexistent/path/that/does/not/exist", nil)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func TestSanitizer_Strategies(t *testing.T) {
+	tests := []struct {
+		strategy string
+		input    string
+		want     string
+	}{
+		{"", "feature/Fix_Bug", "feature-Fix_Bug"},
+		{pathutil.StrategyFlatten, "/feature/x/", "feature-x"},
+		{pathutil.StrategyNested, "feature/auth/login", "feature/auth/login"},
+		{pathutil.StrategyNested, "simple", "simple"},
+		{pathutil.StrategySlug, "Feature/Fix_Bug", "feature-fix-bug"},
+		{pathutil.StrategySlug, "feat: a*b  c", "feat-a-b-c"},
+		{pathutil.StrategySlug, "--Über/x--", "ber-x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+" "+tt.input, func(t *testing.T) {
+			got, err := pathutil.Sanitizer{Strategy: tt.strategy}.Sanitize(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizer_HashSuffix(t *testing.T) {
+	s := pathutil.Sanitizer{Strategy: pathutil.StrategyHashSuffix}
+
+	a, err := s.Sanitize("feat/a-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.Sanitize("feat-a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Errorf("branches that flatten to the same name got the same directory %q", a)
+	}
+	if !strings.HasPrefix(a, "feat-a-b-") || len(a) != len("feat-a-b-")+8 {
+		t.Errorf("got %q, want feat-a-b-<8 hex digits>", a)
+	}
+	if again, _ := s.Sanitize("feat/a-b"); again != a {
+		t.Errorf("hash is not stable: %q, then %q", a, again)
+	}
+}
+
+func TestSanitizer_MaxLength(t *testing.T) {
+	tests := []struct {
+		strategy string
+		input    string
+		want     string
+	}{
+		{pathutil.StrategyFlatten, "feature/abcdefghij", "feature-ab"},
+		{pathutil.StrategyFlatten, "feature/x", "feature-x"},
+		{pathutil.StrategyFlatten, "feature--/x", "feature"}, // trailing hyphens of the cut are trimmed
+		{pathutil.StrategyFlatten, "feature/日本語", "feature"}, // not cut inside a UTF-8 sequence
+		{pathutil.StrategyNested, "feature/abcdefghijkl", "feature/abcdefghij"},
+		{pathutil.StrategySlug, "Feature/ABCDEFGHIJ", "feature-ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+" "+tt.input, func(t *testing.T) {
+			got, err := pathutil.Sanitizer{Strategy: tt.strategy, MaxLength: 10}.Sanitize(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	got, err := pathutil.Sanitizer{Strategy: pathutil.StrategyHashSuffix, MaxLength: 12}.Sanitize("feature/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 12 || !strings.HasPrefix(got, "fea-") {
+		t.Errorf("got %q, want fea-<8 hex digits>", got)
+	}
+	if _, err := (pathutil.Sanitizer{Strategy: pathutil.StrategyHashSuffix, MaxLength: 9}).Sanitize("feature/x"); err == nil {
+		t.Error("expected error for a max length that leaves no room for the name")
+	}
+}
+
+func TestSanitizer_Invalid(t *testing.T) {
+	tests := []struct {
+		strategy string
+		input    string
+	}{
+		{pathutil.StrategyNested, "feature//x"},
+		{pathutil.StrategyNested, "feature/../x"},
+		{pathutil.StrategySlug, "日本語"},
+		{pathutil.StrategyHashSuffix, "/"},
+		{"unknown", "feature/x"},
+	}
+
+	for _, tt := range tests {
+		if got, err := (pathutil.Sanitizer{Strategy: tt.strategy}).Sanitize(tt.input); err == nil {
+			t.Errorf("%s: Sanitize(%q) = %q, expected error", tt.strategy, tt.input, got)
+		}
+	}
+}
+
+func TestRenderPath(t *testing.T) {
+	vars := pathutil.PathVars{
+		RepoName:        "repo",
+		Branch:          "feature/PROJ-123-login",
+		SanitizedBranch: "feature-PROJ-123-login",
+		BranchSegments:  []string{"feature", "PROJ-123-login"},
+		User:            "alice",
+		Date:            "2026-10-18",
+	}
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{pathutil.DefaultPathTemplate, "feature-PROJ-123-login"},
+		{"{{.SanitizedBranch}}", "feature-PROJ-123-login"},
+		{"{{.Branch}}", "feature/PROJ-123-login"},
+		{"{{.User}}/{{index .BranchSegments 1}}", "alice/PROJ-123-login"},
+		{"{{.RepoName}}-wt/{{.Date}}-{{.Branch | sanitize}}", "repo-wt/2026-10-18-feature-PROJ-123-login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			got, err := pathutil.RenderPath("/base", tt.tmpl, vars, pathutil.Sanitizer{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := filepath.Join("/base", tt.want); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRenderPath_Invalid(t *testing.T) {
+	vars := pathutil.NewPathVars("repo", "feature/x", pathutil.Sanitizer{})
+	for _, tmpl := range []string{
+		"../{{.Branch}}",              // escapes the base directory
+		"/tmp/{{.Branch}}",            // absolute
+		"{{.Branch}}/../../../x",      // escapes after cleaning
+		"",                            // the base directory itself
+		"{{.Branch",                   // parse error
+		"{{.Unknown}}",                // unknown variable
+		"{{index .BranchSegments 5}}", // out of range
+	} {
+		if got, err := pathutil.RenderPath("/base", tmpl, vars, pathutil.Sanitizer{}); err == nil {
+			t.Errorf("RenderPath(%q) = %q, expected error", tmpl, got)
+		}
+	}
+}
+
+func TestRenderPath_SanitizeError(t *testing.T) {
+	_, err := pathutil.RenderPath("/base", pathutil.DefaultPathTemplate, pathutil.NewPathVars("repo", "/", pathutil.Sanitizer{}), pathutil.Sanitizer{})
+	if err == nil || !strings.Contains(err.Error(), `invalid branch name "/"`) {
+		t.Errorf("expected sanitize error, got: %v", err)
+	}
+}
+
+func TestResolveCollision(t *testing.T) {
+	base := t.TempDir()
+	free := filepath.Join(base, "free")
+	empty := filepath.Join(base, "empty")
+	taken := filepath.Join(base, "taken")
+	for _, dir := range []string{empty, filepath.Join(taken, "file"), taken + "-2"} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path   string
+		policy string
+		want   string // "" means an error
+	}{
+		{free, pathutil.CollisionError, free},
+		{free, pathutil.CollisionSuffix, free},
+		{taken, "", ""},
+		{taken, pathutil.CollisionError, ""},
+		{taken, pathutil.CollisionSuffix, taken + "-3"},
+		{empty, pathutil.CollisionSuffix, empty + "-2"},
+		{empty, pathutil.CollisionReuseIfEmpty, empty},
+		{taken, pathutil.CollisionReuseIfEmpty, ""},
+		{taken, "unknown", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.path)+" "+tt.policy, func(t *testing.T) {
+			got, err := pathutil.ResolveCollision(tt.path, tt.policy, nil)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("got %q, expected error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccupant(t *testing.T) {
+	owners := map[string]string{"/base/a": "feature/a", "/base/d": ""}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/base/a", `used by the worktree of branch "feature/a"`},
+		{"/base/a/sub", `inside the worktree of branch "feature/a" at /base/a`},
+		{"/base/d", "used by a detached worktree"},
+		{"/base/ab", ""},
+	}
+	for _, tt := range tests {
+		if got := pathutil.Occupant(tt.path, owners); got != tt.want {
+			t.Errorf("Occupant(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
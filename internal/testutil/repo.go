@@ -89,6 +89,54 @@ func (r *TestRepo) CreateWorktree(name, branch string) string {
 	return wtPath
 }
 
+// CreateDetachedWorktree creates a git worktree with a detached HEAD and returns its absolute path.
+func (r *TestRepo) CreateDetachedWorktree(name string) string {
+	r.t.Helper()
+	wtPath := filepath.Join(filepath.Dir(r.Root), name)
+	gitCmd(r.t, r.Root, "worktree", "add", "--detach", wtPath)
+	return wtPath
+}
+
+// LockWorktree locks a worktree with the given reason.
+func (r *TestRepo) LockWorktree(path, reason string) {
+	r.t.Helper()
+	gitCmd(r.t, r.Root, "worktree", "lock", "--reason", reason, path)
+}
+
+// HeadSHA returns the full commit SHA of the given ref.
+func (r *TestRepo) HeadSHA(ref string) string {
+	r.t.Helper()
+	return gitCmd(r.t, r.Root, "rev-parse", ref)
+}
+
+// CommitFile writes a file in dir (the repository root or a worktree) and commits it.
+func (r *TestRepo) CommitFile(dir, name, content string) {
+	r.t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+	gitCmd(r.t, dir, "add", name)
+	gitCmd(r.t, dir, "commit", "-m", "add "+name)
+}
+
+// SetUpstream sets the upstream of a local branch (e.g., "origin/main").
+func (r *TestRepo) SetUpstream(branch, upstream string) {
+	r.t.Helper()
+	gitCmd(r.t, r.Root, "branch", "--set-upstream-to="+upstream, branch)
+}
+
+// MergeBranch fast-forwards the current branch of the main worktree to branch.
+func (r *TestRepo) MergeBranch(branch string) {
+	r.t.Helper()
+	gitCmd(r.t, r.Root, "merge", "--ff-only", branch)
+}
+
+// MergeBranchNoFF merges branch into the current branch of the main worktree with a merge commit.
+func (r *TestRepo) MergeBranchNoFF(branch string) {
+	r.t.Helper()
+	gitCmd(r.t, r.Root, "merge", "--no-ff", "-m", "Merge "+branch, branch)
+}
+
 // WriteConfig writes .gw/config with the given TOML content.
 func (r *TestRepo) WriteConfig(content string) {
 	r.t.Helper()
@@ -102,13 +150,14 @@ func (r *TestRepo) WriteConfig(content string) {
 }
 
 // WriteHook creates a hook script in .gw/hooks/ with execute permission.
+// name may contain a subdirectory, e.g. "post-add.d/10-setup".
 func (r *TestRepo) WriteHook(name, content string) {
 	r.t.Helper()
-	hookDir := filepath.Join(r.Root, ".gw", "hooks")
-	if err := os.MkdirAll(hookDir, 0755); err != nil {
+	hookPath := filepath.Join(r.Root, ".gw", "hooks", name)
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
 		r.t.Fatal(err)
 	}
-	if err := os.WriteFile(filepath.Join(hookDir, name), []byte(content), 0755); err != nil {
+	if err := os.WriteFile(hookPath, []byte(content), 0755); err != nil {
 		r.t.Fatal(err)
 	}
 }
@@ -116,11 +165,11 @@ func (r *TestRepo) WriteHook(name, content string) {
 // WriteHookNoExec creates a hook script without execute permission.
 func (r *TestRepo) WriteHookNoExec(name, content string) {
 	r.t.Helper()
-	hookDir := filepath.Join(r.Root, ".gw", "hooks")
-	if err := os.MkdirAll(hookDir, 0755); err != nil {
+	hookPath := filepath.Join(r.Root, ".gw", "hooks", name)
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
 		r.t.Fatal(err)
 	}
-	if err := os.WriteFile(filepath.Join(hookDir, name), []byte(content), 0644); err != nil {
+	if err := os.WriteFile(hookPath, []byte(content), 0644); err != nil {
 		r.t.Fatal(err)
 	}
 }